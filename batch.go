@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/boltdb/bolt"
+)
+
+// BatchOp is one operation of a /batch request. Keys, bounds and values are
+// given as text, converted with the codecs of the bucket as in the other
+// requests, or base64 encoded in the raw fields, which take precedence.
+type BatchOp struct {
+	// Op is put, delete, deletePrefix, deleteRange, or createBucket or
+	// deleteBucket, which act on Bucket itself. createBucket creates
+	// missing parents too.
+	Op     string     `json:"op"`
+	Bucket BucketPath `json:"bucket"`
+
+	Key      string `json:"key,omitempty"`
+	RawKey   []byte `json:"rawKey,omitempty"`
+	Value    string `json:"value,omitempty"`
+	RawValue []byte `json:"rawValue,omitempty"`
+	Format   string `json:"format,omitempty"`
+
+	// Prefix, From and To select the entries deleted like a listing
+	// filter does: the keys starting with Prefix within [From, To).
+	Prefix    string `json:"prefix,omitempty"`
+	RawPrefix []byte `json:"rawPrefix,omitempty"`
+	From      string `json:"from,omitempty"`
+	RawFrom   []byte `json:"rawFrom,omitempty"`
+	To        string `json:"to,omitempty"`
+	RawTo     []byte `json:"rawTo,omitempty"`
+}
+
+// BatchResult counts the entries a batch wrote and deleted, and the buckets
+// it created and deleted.
+type BatchResult struct {
+	Put            int `json:"put"`
+	Deleted        int `json:"deleted"`
+	CreatedBuckets int `json:"createdBuckets"`
+	DeletedBuckets int `json:"deletedBuckets"`
+}
+
+// batchOp is a BatchOp with its key, value and bounds converted to bytes.
+type batchOp struct {
+	op         string
+	path       BucketPath
+	key, value []byte
+	keys       keyRange
+}
+
+// resolve checks o and converts it to a batchOp.
+func (o BatchOp) resolve() (batchOp, error) {
+	op := batchOp{op: o.Op, path: o.Bucket}
+	if len(o.Bucket) == 0 {
+		return op, errors.New("empty bucket path")
+	}
+
+	var err error
+	switch o.Op {
+	case "put":
+		if op.key, err = opBytes(o.Bucket, o.Key, o.RawKey); err != nil {
+			return op, fmt.Errorf("invalid key: %v", err)
+		}
+		if o.RawValue != nil {
+			op.value = o.RawValue
+		} else if op.value, err = encodeEntry(o.Bucket, o.Value, o.Format); err != nil {
+			return op, err
+		}
+	case "delete":
+		if op.key, err = opBytes(o.Bucket, o.Key, o.RawKey); err != nil {
+			return op, fmt.Errorf("invalid key: %v", err)
+		}
+	case "deletePrefix", "deleteRange":
+		for _, bound := range []struct {
+			dst  *[]byte
+			name string
+			text string
+			raw  []byte
+		}{
+			{&op.keys.prefix, "prefix", o.Prefix, o.RawPrefix},
+			{&op.keys.from, "from", o.From, o.RawFrom},
+			{&op.keys.to, "to", o.To, o.RawTo},
+		} {
+			if bound.text == "" && bound.raw == nil {
+				continue
+			}
+			if *bound.dst, err = opBytes(o.Bucket, bound.text, bound.raw); err != nil {
+				return op, fmt.Errorf("invalid %s: %v", bound.name, err)
+			}
+		}
+
+		// an unbounded delete would empty the bucket by mistake
+		if o.Op == "deletePrefix" && len(op.keys.prefix) == 0 {
+			return op, errors.New("deletePrefix needs a prefix")
+		}
+		if o.Op == "deleteRange" && len(op.keys.from) == 0 && len(op.keys.to) == 0 {
+			return op, errors.New("deleteRange needs from or to")
+		}
+	case "createBucket", "deleteBucket":
+	default:
+		return op, fmt.Errorf("unknown op %q", o.Op)
+	}
+	return op, nil
+}
+
+// opBytes returns raw if it is set and otherwise text converted with the key
+// codec of the bucket at path, like formKey.
+func opBytes(path BucketPath, text string, raw []byte) ([]byte, error) {
+	if raw != nil {
+		return raw, nil
+	}
+	if c, _, _ := codecsFor(path); c != nil {
+		return c.Encode(text)
+	}
+	return []byte(text), nil
+}
+
+// applyBatch applies ops in order in a single transaction, so either all or
+// none of them take effect.
+func applyBatch(ops []batchOp) (BatchResult, error) {
+	var res BatchResult
+	err := db.Update(func(tx *bolt.Tx) error {
+		var err error
+		res, err = applyOps(tx, ops)
+		return err
+	})
+	if err != nil {
+		return BatchResult{}, err
+	}
+	return res, nil
+}
+
+// applyOps applies ops in order in tx.
+func applyOps(tx *bolt.Tx, ops []batchOp) (BatchResult, error) {
+	var res BatchResult
+	for i, op := range ops {
+		if err := op.apply(tx, &res); err != nil {
+			return res, fmt.Errorf("op %d: %w", i, err)
+		}
+	}
+	return res, nil
+}
+
+// apply applies op in tx and counts it in res. Deleting a key that does not
+// exist is no error.
+func (op batchOp) apply(tx *bolt.Tx, res *BatchResult) error {
+	switch op.op {
+	case "createBucket":
+		if _, err := createBucketAt(tx, op.path, true); err != nil {
+			return err
+		}
+		res.CreatedBuckets++
+		return nil
+	case "deleteBucket":
+		if err := deleteBucketAt(tx, op.path); err != nil {
+			return err
+		}
+		res.DeletedBuckets++
+		return nil
+	}
+
+	buck, err := op.path.bucket(tx)
+	if err != nil {
+		return err
+	}
+
+	var keys [][]byte
+	switch op.op {
+	case "put":
+		if err := buck.Put(op.key, op.value); err != nil {
+			return err
+		}
+		res.Put++
+		return nil
+	case "delete":
+		if buck.Get(op.key) != nil {
+			keys = append(keys, op.key)
+		}
+	default:
+		// collected first, as deleting moves the cursor
+		c := buck.Cursor()
+		for k, v := op.keys.first(c, nil); k != nil && op.keys.contains(k); k, v = c.Next() {
+			if v != nil {
+				keys = append(keys, append([]byte{}, k...))
+			}
+		}
+	}
+
+	for _, k := range keys {
+		if err := buck.Delete(k); err != nil {
+			return err
+		}
+	}
+	res.Deleted += len(keys)
+	return nil
+}
+
+// parseOps reads the ops form value as a list of BatchOps and resolves
+// them.
+func parseOps(r *http.Request) ([]batchOp, error) {
+	var ops []BatchOp
+	if err := json.Unmarshal([]byte(r.FormValue("ops")), &ops); err != nil {
+		return nil, fmt.Errorf("invalid ops: %v", err)
+	}
+	if len(ops) == 0 {
+		return nil, errors.New("no ops")
+	}
+
+	resolved := make([]batchOp, len(ops))
+	for i, o := range ops {
+		op, err := o.resolve()
+		if err != nil {
+			return nil, fmt.Errorf("op %d: %v", i, err)
+		}
+		resolved[i] = op
+	}
+	return resolved, nil
+}
+
+func batchHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	ops, err := parseOps(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := applyBatch(ops)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, res)
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -8,386 +12,922 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/boltdb/bolt"
-	msgpack "gopkg.in/vmihailenco/msgpack.v2"
 )
 
 //go:generate esc -o html.go html
 
 const (
-	delimiter = "--"
+	defaultPageSize = 100
+	maxPageSize     = 1000
 )
 
 var (
 	curDir string
 
-	port   = flag.String("port", "8080", "Set port for server.")
-	dbpath = flag.String("path", "path-to-db", "Set path to bolt db file.")
-	coding = flag.String("coding", "text", "Type of value encding [text, mspack]")
+	// db is opened once in main and shared by all handlers.
+	db *bolt.DB
+
+	// dbLock is held for reading by handlers using db, and for writing
+	// while compaction replaces it.
+	dbLock sync.RWMutex
+
+	// quit is signalled to shut the server down and close db.
+	quit = make(chan os.Signal, 1)
+
+	port       = flag.String("port", "8080", "Set port for server.")
+	dbpath     = flag.String("path", "path-to-db", "Set path to bolt db file.")
+	coding     = flag.String("coding", "text", "Codec of values, e.g. text, mspack, json, hex, uint64be, or auto to detect.")
+	timeout    = flag.Duration("timeout", 5*time.Second, "Time to wait for the lock on the db file, 0 waits forever.")
+	configPath = flag.String("config", "", "File with per-bucket codec rules, <path>.boltgui.json by default.")
+	readonly   = flag.Bool("readonly", false, "Open the db read-only. Other read-only readers may use it at the same time.")
+	protos     = flag.String("proto", "", "Comma separated FileDescriptorSets or .proto files with message types for proto:<type> codecs.")
+	snapshot   = flag.String("snapshot", "", "Write a consistent copy of the db to this file and exit, without starting the server.")
 )
 
 func main() {
 	curDir, _ = filepath.Abs(filepath.Dir(os.Args[0]))
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			exportMain(os.Args[2:])
+			return
+		case "import":
+			importMain(os.Args[2:])
+			return
+		case "compact":
+			compactMain(os.Args[2:])
+			return
+		}
+	}
+
 	flag.Parse()
+	setup(flag.CommandLine)
 
-	if *dbpath == "path-to-db" {
-		fmt.Print("Parameter -path must be set")
-		flag.Usage()
+	if *snapshot != "" {
+		openDB(true)
+		err := writeSnapshot(*snapshot)
+		db.Close()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 		return
 	}
 
 	http.HandleFunc("/exit", exit)
-	http.HandleFunc("/getBuckets", getBucketsHandler)
-	http.HandleFunc("/getEntries", getEntriesHandler)
-	http.HandleFunc("/delEntry", delEntryHandler)
-	http.HandleFunc("/delBucket", delBucketHandler)
-	http.HandleFunc("/setEntry", setEntryHandler)
-	http.HandleFunc("/setBucket", setBucketHandler)
+	http.HandleFunc("/getInfo", getInfoHandler)
+	http.HandleFunc("/getConfig", getConfigHandler)
+	http.HandleFunc("/setConfig", setConfigHandler)
+	http.HandleFunc("/getBuckets", usingDB(getBucketsHandler))
+	http.HandleFunc("/getEntries", usingDB(getEntriesHandler))
+	http.HandleFunc("/delEntry", usingDB(writable(delEntryHandler)))
+	http.HandleFunc("/delBucket", usingDB(writable(delBucketHandler)))
+	http.HandleFunc("/setEntry", usingDB(writable(setEntryHandler)))
+	http.HandleFunc("/renameEntry", usingDB(writable(renameEntryHandler)))
+	http.HandleFunc("/copyEntries", usingDB(writable(copyEntriesHandler)))
+	http.HandleFunc("/batch", usingDB(writable(batchHandler)))
+	http.HandleFunc("/stage", usingDB(writable(stageHandler)))
+	http.HandleFunc("/getStage", usingDB(getStageHandler))
+	http.HandleFunc("/commitStage", usingDB(writable(commitStageHandler)))
+	http.HandleFunc("/discardStage", writable(discardStageHandler))
+	http.HandleFunc("/setBucket", usingDB(writable(setBucketHandler)))
+	http.HandleFunc("/moveBucket", usingDB(writable(moveBucketHandler)))
+	http.HandleFunc("/search", usingDB(searchHandler))
+	http.HandleFunc("/export", usingDB(exportHandler))
+	http.HandleFunc("/import", usingDB(importHandler))
+	http.HandleFunc("/snapshot", usingDB(snapshotHandler))
+	http.HandleFunc("/getStats", usingDB(getStatsHandler))
+	http.HandleFunc("/compact", writable(compactHandler))
 
 	http.Handle("/", http.FileServer(Dir(false, "/html")))
 	//http.Handle("/", http.FileServer(http.Dir("html")))
 
-	http.ListenAndServe(":"+*port, nil)
+	openDB(*readonly)
+
+	srv := &http.Server{Addr: ":" + *port}
+
+	// drained is closed once the handlers under way have returned, or
+	// given up on after the shutdown timeout
+	drained := make(chan struct{})
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-quit
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			// cut the connections left, which cancels searches and
+			// downloads still reading db
+			log.Println(err)
+			srv.Close()
+		}
+		close(drained)
+	}()
+
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		log.Println(err)
+	} else {
+		<-drained
+	}
+
+	// a compaction still running may be replacing db; the lock is kept
+	// until the process exits
+	dbLock.Lock()
+	if err := db.Close(); err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+}
+
+// subcommand returns the flag set of a subcommand, which takes the flags
+// selecting the db and its codecs as well.
+func subcommand(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		switch f.Name {
+		case "port", "readonly", "snapshot":
+		default:
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	return fs
+}
+
+// setup checks the flags parsed by fs and loads the codec configuration.
+// It exits on failure.
+func setup(fs *flag.FlagSet) {
+	if *dbpath == "path-to-db" {
+		fmt.Println("Parameter -path must be set")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if *protos != "" {
+		if err := loadProtos(*protos); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	if _, err := lookupCodec(*coding); err != nil {
+		fmt.Println(err)
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := loadConfig(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// openDB opens the database at -path into db. It exits on failure.
+func openDB(readOnly bool) {
+	var err error
+	db, err = bolt.Open(*dbpath, 0600, &bolt.Options{
+		Timeout:  *timeout,
+		ReadOnly: readOnly,
+	})
+	if err != nil {
+		fmt.Printf("Cannot open %s: %v\n", *dbpath, err)
+		os.Exit(1)
+	}
+}
+
+// usingDB wraps a handler that uses db so that db is not replaced while it
+// runs.
+func usingDB(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbLock.RLock()
+		defer dbLock.RUnlock()
+		h(w, r)
+	}
+}
+
+// writable wraps a handler that modifies the database so that it rejects
+// all requests when the database is opened read-only.
+func writable(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if *readonly {
+			writeError(w, http.StatusForbidden, bolt.ErrDatabaseReadOnly)
+			return
+		}
+		h(w, r)
+	}
 }
 
 func delEntryHandler(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
-	delEntry(r.FormValue("bucket"), r.FormValue("key"))
+	path, err := parseBucketPath(r.FormValue("bucket"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	key, err := formKey(r, path, "key", "rawKey")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := delEntry(path, key); err != nil {
+		writeError(w, errorStatus(err), err)
+	}
 }
 
 func delBucketHandler(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
-	delBucket(r.FormValue("bucket"))
+	path, err := parseBucketPath(r.FormValue("bucket"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := delBucket(path); err != nil {
+		writeError(w, errorStatus(err), err)
+	}
 }
 
 func setEntryHandler(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
-	setEntry(r.FormValue("bucket"), r.FormValue("key"), r.FormValue("value"))
+	path, err := parseBucketPath(r.FormValue("bucket"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	key, err := formKey(r, path, "key", "rawKey")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var value []byte
+	if _, ok := r.Form["rawValue"]; ok {
+		value, err = formBytes(r, "value", "rawValue")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		value, err = encodeEntry(path, r.FormValue("value"), r.FormValue("format"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	entry, err := setEntry(path, key, value)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, entry)
 }
 
 func setBucketHandler(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
-	setBucket(r.FormValue("bucket"))
-}
-
-func getBucketsHandler(w http.ResponseWriter, r *http.Request) {
-	buckets := getBuckets()
-
-	js, err := json.Marshal(buckets)
+	path, err := parseBucketPath(r.FormValue("bucket"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(js)
+	if err := setBucket(path, r.FormValue("parents") == "true"); err != nil {
+		writeError(w, errorStatus(err), err)
+	}
 }
 
-func getEntriesHandler(w http.ResponseWriter, r *http.Request) {
-	entries := getEntries(r.URL.Query().Get("buck"))
+// Info describes the open database to the UI.
+type Info struct {
+	Path     string `json:"path"`
+	ReadOnly bool   `json:"readOnly"`
+	Codec    string `json:"codec"`
+}
 
-	js, err := json.Marshal(entries)
+func getInfoHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, Info{
+		Path:     *dbpath,
+		ReadOnly: *readonly,
+		Codec:    *coding,
+	})
+}
+
+func getBucketsHandler(w http.ResponseWriter, r *http.Request) {
+	buckets, err := getBuckets()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, errorStatus(err), err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(js)
+	writeJSON(w, buckets)
 }
 
-func exit(w http.ResponseWriter, r *http.Request) {
-	os.Exit(0)
-}
+func getEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	path, err := parseBucketPath(r.URL.Query().Get("bucket"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
 
-func delEntry(bucket, key string) {
-	db := getDb()
-	defer db.Close()
+	var keys keyRange
+	for _, bound := range []struct {
+		dst           *[]byte
+		name, rawName string
+	}{
+		{&keys.prefix, "prefix", "rawPrefix"},
+		{&keys.from, "from", "rawFrom"},
+		{&keys.to, "to", "rawTo"},
+	} {
+		if r.FormValue(bound.name) == "" && r.FormValue(bound.rawName) == "" {
+			continue
+		}
 
-	err := db.Update(func(tx *bolt.Tx) error {
-		buck, err := getBucketByFullName(bucket, tx)
+		// text bounds are typed like keys, through the key codec
+		*bound.dst, err = formKey(r, path, bound.name, bound.rawName)
 		if err != nil {
-			return err
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid %s: %v", bound.name, err))
+			return
 		}
+	}
 
-		return buck.Delete([]byte(key))
-	})
-
+	start, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("start"))
 	if err != nil {
-		panic(err)
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid start: %v", err))
+		return
 	}
-}
-
-func delBucket(fullName string) {
-	db := getDb()
-	defer db.Close()
 
-	err := db.Update(func(tx *bolt.Tx) error {
-		fullName = strings.TrimPrefix(fullName, "list--")
-		bucketChain := strings.Split(fullName, delimiter)
+	forward := true
+	switch r.URL.Query().Get("dir") {
+	case "", "next":
+	case "prev":
+		forward = false
+	default:
+		writeError(w, http.StatusBadRequest, errors.New("dir must be next or prev"))
+		return
+	}
 
-		if len(bucketChain) < 1 {
-			return errors.New("empty bucket list")
+	limit := defaultPageSize
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", maxPageSize))
+			return
 		}
+	}
 
-		var (
-			buck        = &bolt.Bucket{}
-			deletingKey string
-		)
+	page, err := getEntries(path, keys, start, forward, limit)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
 
-		if len(bucketChain) == 1 {
-			return tx.DeleteBucket([]byte(bucketChain[0]))
-		}
-
-		for i, bucketName := range bucketChain {
-			switch {
-			case i == 0: //first level bucket get from tx
-				buck = tx.Bucket([]byte(bucketName))
-			case i == len(bucketChain)-1: // save name of last bucket in chain and parent it's parent bucket
-				deletingKey = bucketName
-			default:
-				if buck == nil {
-					return errors.New("Bucket not found.")
-				}
-				buck = buck.Bucket([]byte(bucketName))
-			}
+	writeJSON(w, page)
+}
+
+func exit(w http.ResponseWriter, r *http.Request) {
+	select {
+	case quit <- os.Interrupt:
+	default: // shutdown already under way
+	}
+}
 
+func delEntry(path BucketPath, key []byte) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		buck, err := path.bucket(tx)
+		if err != nil {
+			return err
 		}
 
-		return buck.DeleteBucket([]byte(deletingKey))
+		return buck.Delete(key)
 	})
+}
 
+func delBucket(path BucketPath) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		return deleteBucketAt(tx, path)
+	})
+}
+
+// deleteBucketAt deletes the bucket at path in tx.
+func deleteBucketAt(tx *bolt.Tx, path BucketPath) error {
+	parent, name := path.split()
+	if len(parent) == 0 {
+		return tx.DeleteBucket(name)
+	}
+
+	buck, err := parent.bucket(tx)
 	if err != nil {
-		panic(err)
+		return err
 	}
-}
 
-func setEntry(bucket, key, value string) {
-	db := getDb()
-	defer db.Close()
+	return buck.DeleteBucket(name)
+}
 
+func setEntry(path BucketPath, key, value []byte) (Entry, error) {
 	err := db.Update(func(tx *bolt.Tx) error {
-		buck, err := getBucketByFullName(bucket, tx)
+		buck, err := path.bucket(tx)
 		if err != nil {
 			return err
 		}
 
-		return buck.Put([]byte(key), encodeEntry(value))
+		return buck.Put(key, value)
 	})
 
 	if err != nil {
-		panic(err)
+		return Entry{}, err
 	}
-}
 
-func setBucket(bucket string) {
-	db := getDb()
-	defer db.Close()
+	return decodeEntry(path, key, value), nil
+}
 
-	err := db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucket([]byte(bucket))
+// setBucket creates the bucket at path. With parents, missing buckets
+// leading to it are created too; otherwise its parent must exist.
+func setBucket(path BucketPath, parents bool) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		_, err := createBucketAt(tx, path, parents)
 		return err
 	})
+}
+
+// createBucketAt creates the bucket at path in tx, and with parents the
+// buckets missing on the way.
+func createBucketAt(tx *bolt.Tx, path BucketPath, parents bool) (*bolt.Bucket, error) {
+	parent, name := path.split()
+	if len(parent) == 0 {
+		return tx.CreateBucket(name)
+	}
 
+	var buck *bolt.Bucket
+	var err error
+	if parents {
+		buck, err = parent.createBucket(tx)
+	} else {
+		buck, err = parent.bucket(tx)
+	}
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-}
 
-func getEntries(bucketName string) Bucket {
-	db := getDb()
-	defer db.Close()
+	return buck.CreateBucket(name)
+}
 
-	resultBucket := Bucket{
-		Name:       bucketName,
+// getEntries returns up to limit rows of the bucket at path within keys, in
+// key order. Going forward the page starts at start (or the first key);
+// going backward it ends just before start (or at the last key). Nested
+// buckets are returned by name only and count as rows.
+func getEntries(path BucketPath, keys keyRange, start []byte, forward bool, limit int) (Page, error) {
+	page := Page{
 		Subbuckets: []Bucket{},
 		Entries:    []Entry{},
 	}
 
-	db.View(func(tx *bolt.Tx) error {
-		curBucket := tx.Bucket([]byte(bucketName))
+	err := db.View(func(tx *bolt.Tx) error {
+		curBucket, err := path.bucket(tx)
+		if err != nil {
+			return err
+		}
 
-		resultBucket.fill(curBucket)
-		return nil
-	})
-	return resultBucket
-}
+		type row struct{ k, v []byte }
 
-func getBuckets() []string {
-	db := getDb()
-	defer db.Close()
+		var (
+			c    = curBucket.Cursor()
+			next = c.Next
+			rows []row
+			k, v []byte
+		)
+
+		if forward {
+			k, v = keys.first(c, start)
+		} else {
+			k, v = keys.last(c, start)
+			next = c.Prev
+		}
 
-	bucketsList := []string{}
-	db.View(func(tx *bolt.Tx) error {
-		tx.ForEach(func(name []byte, b *bolt.Bucket) error {
-			bucketsList = append(bucketsList, string(name))
+		for ; k != nil && keys.contains(k) && len(rows) < limit; k, v = next() {
+			rows = append(rows, row{k, v})
+		}
+
+		if len(rows) == 0 {
 			return nil
-		})
+		}
+
+		if !forward {
+			for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+				rows[i], rows[j] = rows[j], rows[i]
+			}
+		}
+
+		for _, r := range rows {
+			if r.v == nil { //subbucket
+				page.Subbuckets = append(page.Subbuckets, newBucket(r.k))
+			} else {
+				page.Entries = append(page.Entries, decodeEntry(path, r.k, r.v))
+			}
+		}
+
+		first, last := rows[0].k, rows[len(rows)-1].k
+		c.Seek(first)
+		if k, _ := c.Prev(); k != nil && keys.contains(k) {
+			page.Prev = append([]byte{}, first...)
+		}
+		c.Seek(last)
+		if k, _ := c.Next(); k != nil && keys.contains(k) {
+			page.Next = append([]byte{}, k...)
+		}
 		return nil
 	})
-	return bucketsList
+
+	return page, err
 }
 
-func getDb() *bolt.DB {
-	db, err := bolt.Open(*dbpath, 0600, nil)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-	return db
+func getBuckets() ([]Bucket, error) {
+	bucketsList := []Bucket{}
+	err := db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			bucketsList = append(bucketsList, newBucket(name))
+			return nil
+		})
+	})
+	return bucketsList, err
 }
 
+// Entry is a single key/value pair. Key and Value hold a printable
+// rendering, RawKey and RawValue the stored bytes (base64 in JSON), so
+// that clients can send back exactly what they received.
 type Entry struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	RawKey   []byte `json:"rawKey"`
+	RawValue []byte `json:"rawValue"`
+	Binary   bool   `json:"binary"`
+	Codec    string `json:"codec"`
+
+	// Size is the length of the stored value, UncompressedSize that of the
+	// value inside when the codec decompresses it.
+	Size             int `json:"size"`
+	UncompressedSize int `json:"uncompressedSize,omitempty"`
+
+	// Lossy is set when Value does not encode back to the stored bytes, so
+	// that saving it, even unedited, would change them.
+	Lossy bool `json:"lossy,omitempty"`
+
+	// Format and Confidence are set when the codec guesses the format of
+	// every value, as "auto" does.
+	Format     string  `json:"format,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
 }
 
-// Bucket TODO
+// Bucket is a bucket reference as listed in the UI.
 type Bucket struct {
-	Name       string   `json:"name"`
-	Subbuckets []Bucket `json:"subbuckets"`
-	Entries    []Entry  `json:"entries"`
+	Name    string `json:"name"`
+	RawName []byte `json:"rawName"`
 }
 
-func (b *Bucket) fill(bucket *bolt.Bucket) {
-	//fmt.Println("Fill bucket ", bucket.Root())
-	//fill subbuckets
-	// bucket.Tx().ForEach(func(name []byte, buck *bolt.Bucket) error {
-	// 	fmt.Println("subbucket ", string(name))
-	// 	subbuck := Bucket{
-	// 		Name: string(name),
-	// 	}
-	// 	fmt.Println("start fill ", string(name))
-	// 	subbuck.fill(buck)
-
-	// 	b.Subbuckets = append(b.Subbuckets, subbuck)
-	// 	return nil
-	// })
-
-	//fill entries
-	bucket.ForEach(func(k, v []byte) error {
-		if len(v) == 0 { //subbucket
-			sb := bucket.Bucket(k)
-			if sb == nil {
-				b.Entries = append(b.Entries, Entry{string(k), string(v)})
-				return nil
-			}
+func newBucket(name []byte) Bucket {
+	return Bucket{
+		Name:    printable(name),
+		RawName: append([]byte{}, name...),
+	}
+}
 
-			subbuck := Bucket{
-				Name:       string(k),
-				Subbuckets: []Bucket{},
-				Entries:    []Entry{},
-			}
-			subbuck.fill(sb)
+// keyRange restricts a listing to the keys starting with prefix that lie in
+// [from, to). Empty bounds are open.
+type keyRange struct {
+	prefix, from, to []byte
+}
 
-			b.Subbuckets = append(b.Subbuckets, subbuck)
+func (r keyRange) contains(k []byte) bool {
+	return bytes.HasPrefix(k, r.prefix) &&
+		bytes.Compare(k, r.from) >= 0 &&
+		(len(r.to) == 0 || bytes.Compare(k, r.to) < 0)
+}
 
-		} else {
-			b.Entries = append(b.Entries, decodeEntry(k, v))
+// first positions c on the first key of the range at or after start.
+func (r keyRange) first(c *bolt.Cursor, start []byte) ([]byte, []byte) {
+	seek := r.prefix
+	for _, b := range [][]byte{r.from, start} {
+		if bytes.Compare(b, seek) > 0 {
+			seek = b
 		}
-		return nil
-	})
+	}
+
+	if len(seek) == 0 {
+		return c.First()
+	}
+	return c.Seek(seek)
 }
 
-func encodeEntry(value string) []byte {
-	switch *coding {
+// last positions c on the last key of the range before start.
+func (r keyRange) last(c *bolt.Cursor, start []byte) ([]byte, []byte) {
+	var end []byte
+	for _, b := range [][]byte{r.to, prefixEnd(r.prefix), start} {
+		if len(b) > 0 && (end == nil || bytes.Compare(b, end) < 0) {
+			end = b
+		}
+	}
 
-	case "text":
-		return []byte(value)
-	case "mspack":
-		var v interface{}
+	if end == nil {
+		return c.Last()
+	}
+	if k, _ := c.Seek(end); k == nil {
+		return c.Last()
+	}
+	return c.Prev()
+}
 
-		err := json.Unmarshal([]byte(value), &v)
-		if err != nil {
-			log.Println(err)
+// prefixEnd returns the smallest key greater than every key starting with
+// prefix, or nil if there is none.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
 		}
+	}
+	return nil
+}
 
-		b, err := msgpack.Marshal(v)
-		if err != nil {
-			log.Println(err)
-		}
+// Page is one window of a bucket listing. Next is the start of the
+// following page and Prev the end of the preceding one; each is omitted at
+// the respective end of the bucket.
+type Page struct {
+	Subbuckets []Bucket `json:"subbuckets"`
+	Entries    []Entry  `json:"entries"`
+	Next       []byte   `json:"next,omitempty"`
+	Prev       []byte   `json:"prev,omitempty"`
+}
 
-		return b
+// encodeEntry converts value with the value codec of the bucket at path.
+// format is the format a detecting codec reported when the value was read,
+// other codecs ignore it.
+func encodeEntry(path BucketPath, value, format string) ([]byte, error) {
+	_, c, name := codecsFor(path)
+	if c == nil {
+		return nil, fmt.Errorf("unknown codec %q", name)
 	}
+	return encodeValue(c, value, format)
+}
 
-	return nil
+func encodeValue(c Codec, value, format string) ([]byte, error) {
+	if _, ok := c.(formatDetector); ok && format != "" {
+		c = formatCodec(format)
+	}
+	return c.Encode(value)
 }
 
-func decodeEntry(key, value []byte) Entry {
-	switch *coding {
+// decodeEntry renders key and value, stored in the bucket at path, for the
+// UI. Keys and values the codecs cannot decode are shown as printable bytes.
+func decodeEntry(path BucketPath, key, value []byte) Entry {
+	// key and value point into the mmap and are only valid inside the
+	// transaction, while the entry outlives it.
+	entry := Entry{
+		Key:      printable(key),
+		RawKey:   append([]byte{}, key...),
+		RawValue: append([]byte{}, value...),
+		Size:     len(value),
+	}
 
-	case "text":
-		return Entry{
-			Key:   string(key),
-			Value: string(value),
-		}
-	case "mspack":
-		var v interface{}
+	keyCodec, valueCodec, name := codecsFor(path)
+	entry.Codec = name
 
-		err := msgpack.Unmarshal(value, &v)
-		if err != nil {
-			log.Println(err)
+	if keyCodec != nil {
+		if k, err := keyCodec.Decode(key); err == nil {
+			entry.Key = k
 		}
+	}
 
-		switch value := v.(type) {
+	if valueCodec == nil {
+		entry.Value = printable(value)
+		entry.Binary = true
+		return entry
+	}
 
-		case map[interface{}]interface{}:
-			v = toStringMap(value)
-		}
+	c := valueCodec
+	if d, ok := c.(formatDetector); ok {
+		det := d.Detect(value)
+		entry.Format, entry.Confidence = det.Format, det.Confidence
+		c = formatCodec(det.Format)
+	}
 
-		b, err := json.Marshal(v)
-		if err != nil {
-			log.Println(err)
+	// decompress first, to report the size
+	data := value
+	var err error
+	cc, compressed := c.(compressedCodec)
+	if compressed {
+		if data, err = cc.decompress(value); err == nil {
+			entry.UncompressedSize = len(data)
 		}
+		c = cc.codec
+	}
 
-		return Entry{
-			Key:   string(key),
-			Value: string(b),
-		}
+	if err == nil {
+		entry.Value, err = c.Decode(data)
+	}
+	if err != nil {
+		entry.Value = printable(value)
+		entry.Binary = true
+		return entry
 	}
 
-	return Entry{}
+	// saving the value unedited must not change it, which for compressed
+	// values also takes compressing it to the same bytes again
+	b, err := c.Encode(entry.Value)
+	if err == nil && compressed {
+		b, err = cc.compress(b)
+	}
+	entry.Lossy = err != nil || !bytes.Equal(b, value)
+	return entry
 }
 
-func getBucketByFullName(fullName string, tx *bolt.Tx) (*bolt.Bucket, error) {
-	fullName = strings.TrimPrefix(fullName, "list--")
-	bucketChain := strings.Split(fullName, delimiter)
-	if len(bucketChain) < 1 {
-		return nil, errors.New("empty bucket list")
+// printable renders b as text when it is readable and as 0x-prefixed hex
+// otherwise.
+func printable(b []byte) string {
+	if isText(b) {
+		return string(b)
 	}
+	return "0x" + hex.EncodeToString(b)
+}
 
-	buck := &bolt.Bucket{}
+// isText reports whether b is valid UTF-8 without control characters other
+// than whitespace.
+func isText(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	return bytes.IndexFunc(b, func(r rune) bool {
+		return unicode.IsControl(r) && !unicode.IsSpace(r)
+	}) < 0
+}
+
+// formKey returns the key sent in the form. A text key is converted with
+// the key codec of the bucket at path, a raw one is taken as is.
+func formKey(r *http.Request, path BucketPath, name, rawName string) ([]byte, error) {
+	if _, ok := r.Form[rawName]; ok {
+		return formBytes(r, name, rawName)
+	}
 
-	for i, bucketName := range bucketChain {
+	if c, _, _ := codecsFor(path); c != nil {
+		return c.Encode(r.FormValue(name))
+	}
+	return []byte(r.FormValue(name)), nil
+}
+
+// formBytes returns the form value name as bytes. A base64 encoded rawName
+// takes precedence, so that data which is not valid UTF-8 survives the trip.
+func formBytes(r *http.Request, name, rawName string) ([]byte, error) {
+	if raw, ok := r.Form[rawName]; ok && len(raw) > 0 {
+		return base64.StdEncoding.DecodeString(raw[0])
+	}
+	return []byte(r.FormValue(name)), nil
+}
+
+// BucketPath addresses a possibly nested bucket by the names of the buckets
+// leading to it, outermost first. In JSON it is an array of base64 encoded
+// names, so names may contain any bytes.
+type BucketPath [][]byte
+
+func parseBucketPath(s string) (BucketPath, error) {
+	var path BucketPath
+	if err := json.Unmarshal([]byte(s), &path); err != nil {
+		return nil, fmt.Errorf("invalid bucket path: %v", err)
+	}
+	if len(path) == 0 {
+		return nil, errors.New("empty bucket path")
+	}
+	return path, nil
+}
+
+// bucket walks tx down the path and returns the last bucket on it.
+func (p BucketPath) bucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	var buck *bolt.Bucket
+
+	for i, name := range p {
 		if i == 0 { //first level bucket get from tx
-			buck = tx.Bucket([]byte(bucketName))
-		} else { //else serch for subbucket
-			if buck == nil {
-				return nil, errors.New("Bucket not found.")
-			}
-			buck = buck.Bucket([]byte(bucketName))
+			buck = tx.Bucket(name)
+		} else { //else search for subbucket
+			buck = buck.Bucket(name)
 		}
+
+		if buck == nil {
+			return nil, fmt.Errorf("bucket %s: %w", p[:i+1], bolt.ErrBucketNotFound)
+		}
+	}
+
+	if buck == nil {
+		return nil, errors.New("empty bucket path")
 	}
 	return buck, nil
 }
 
-func toStringMap(source map[interface{}]interface{}) map[string]interface{} {
-	var result = map[string]interface{}{}
+// createBucket is bucket, creating the buckets on the path that do not
+// exist.
+func (p BucketPath) createBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	if len(p) == 0 {
+		return nil, errors.New("empty bucket path")
+	}
 
-	for k, v := range source {
-		strKey := fmt.Sprint(k)
-		switch value := v.(type) {
+	buck, err := tx.CreateBucketIfNotExists(p[0])
+	for i := 1; err == nil && i < len(p); i++ {
+		buck, err = buck.CreateBucketIfNotExists(p[i])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bucket %s: %w", p, err)
+	}
+	return buck, nil
+}
 
-		case map[interface{}]interface{}:
-			result[strKey] = toStringMap(value)
-		default:
-			result[strKey] = v
-		}
+// split returns the path of the parent bucket and the name of the last one.
+func (p BucketPath) split() (BucketPath, []byte) {
+	if len(p) == 0 {
+		return nil, nil
+	}
+	return p[:len(p)-1], p[len(p)-1]
+}
+
+// child returns the path of the bucket name nested in p. It never shares
+// its backing array with p.
+func (p BucketPath) child(name []byte) BucketPath {
+	return append(p[:len(p):len(p)], name)
+}
+
+func (p BucketPath) String() string {
+	names := make([]string, len(p))
+	for i, name := range p {
+		names[i] = printable(name)
+	}
+	return strings.Join(names, "/")
+}
+
+// parseDisplayPath parses a bucket path as String renders it. A name is
+// taken as hex only where printable would have rendered it so; names
+// containing a slash cannot be given.
+func parseDisplayPath(s string) (BucketPath, error) {
+	if s == "" {
+		return nil, errors.New("empty bucket path")
+	}
 
+	var path BucketPath
+	for _, name := range strings.Split(s, "/") {
+		if strings.HasPrefix(name, "0x") {
+			if b, err := hex.DecodeString(name[2:]); err == nil && !isText(b) {
+				path = append(path, b)
+				continue
+			}
+		}
+		path = append(path, []byte(name))
 	}
+	return path, nil
+}
 
-	return result
+// walkFunc is called by walk for every key/value pair that is not a nested
+// bucket, with the path of the bucket holding it.
+type walkFunc func(path BucketPath, k, v []byte) error
+
+// walk calls fn for every entry of buck and its nested buckets, depth first
+// in key order. It stops at the first error returned by fn.
+func walk(buck *bolt.Bucket, path BucketPath, fn walkFunc) error {
+	return buck.ForEach(func(k, v []byte) error {
+		if v == nil { //subbucket
+			return walk(buck.Bucket(k), path.child(k), fn)
+		}
+		return fn(path, k, v)
+	})
+}
+
+// walkAll is walk over every bucket of tx.
+func walkAll(tx *bolt.Tx, fn walkFunc) error {
+	return tx.ForEach(func(name []byte, buck *bolt.Bucket) error {
+		return walk(buck, BucketPath{name}, fn)
+	})
+}
+
+// walkPath walks the bucket at path, or the whole database when path is
+// empty, in a single read transaction.
+func walkPath(path BucketPath, fn walkFunc) error {
+	return db.View(func(tx *bolt.Tx) error {
+		if len(path) == 0 {
+			return walkAll(tx, fn)
+		}
+
+		buck, err := path.bucket(tx)
+		if err != nil {
+			return err
+		}
+		return walk(buck, path, fn)
+	})
 }
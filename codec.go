@@ -0,0 +1,550 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Codec converts stored bytes to the text shown and edited in the UI, and
+// that text back to bytes.
+type Codec interface {
+	Decode(b []byte) (string, error)
+	Encode(s string) ([]byte, error)
+}
+
+// codecs holds every codec by the name it is selected with.
+var codecs = map[string]Codec{
+	"text":    textCodec{},
+	"json":    jsonCodec{},
+	"hex":     hexCodec{},
+	"hexdump": hexdumpCodec{},
+	"base64":  base64Codec{},
+	"mspack":  msgpackCodec{},
+	"msgpack": msgpackCodec{},
+	"cbor":    cborCodec{},
+	"bson":    bsonCodec{},
+
+	"uint16be": intCodec{2, binary.BigEndian, false},
+	"uint16le": intCodec{2, binary.LittleEndian, false},
+	"uint32be": intCodec{4, binary.BigEndian, false},
+	"uint32le": intCodec{4, binary.LittleEndian, false},
+	"uint64be": intCodec{8, binary.BigEndian, false},
+	"uint64le": intCodec{8, binary.LittleEndian, false},
+	"int16be":  intCodec{2, binary.BigEndian, true},
+	"int16le":  intCodec{2, binary.LittleEndian, true},
+	"int32be":  intCodec{4, binary.BigEndian, true},
+	"int32le":  intCodec{4, binary.LittleEndian, true},
+	"int64be":  intCodec{8, binary.BigEndian, true},
+	"int64le":  intCodec{8, binary.LittleEndian, true},
+	"uvarint":  varintCodec{false},
+	"varint":   varintCodec{true},
+
+	"unix32be":      timeCodec{4, time.Second},
+	"unix64be":      timeCodec{8, time.Second},
+	"unixmilli64be": timeCodec{8, time.Millisecond},
+	"unixnano64be":  timeCodec{8, time.Nanosecond},
+	"uuid":          uuidCodec{},
+}
+
+// registerCodec makes c available under name. It panics if the name is
+// taken, so that two formats never silently shadow each other.
+func registerCodec(name string, c Codec) {
+	if _, dup := codecs[name]; dup {
+		panic("codec " + name + " registered twice")
+	}
+	codecs[name] = c
+}
+
+// lookupCodec returns the codec registered as name, the tuple codec
+// described by a "tuple:" name, the protobuf codec of a "proto:" message
+// type, or a codec stacked under compressors, as in "gzip+json".
+func lookupCodec(name string) (Codec, error) {
+	if strings.Contains(name, "+") {
+		return parseCompressed(name)
+	}
+	if strings.HasPrefix(name, tuplePrefix) {
+		return parseTuple(strings.TrimPrefix(name, tuplePrefix))
+	}
+	if strings.HasPrefix(name, protoPrefix) {
+		return lookupProto(name)
+	}
+
+	c, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown codec %q, available: %s", name, strings.Join(codecNames(), ", "))
+	}
+	return c, nil
+}
+
+// codecNames returns the names of all registered codecs and loaded
+// protobuf message types, sorted.
+func codecNames() []string {
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	names = append(names, protoMessageNames()...)
+	sort.Strings(names)
+	return names
+}
+
+type textCodec struct{}
+
+func (textCodec) Decode(b []byte) (string, error) {
+	if !isText(b) {
+		return "", errors.New("value is not text")
+	}
+	return string(b), nil
+}
+
+func (textCodec) Encode(s string) ([]byte, error) {
+	return []byte(s), nil
+}
+
+// jsonCodec pretty prints JSON values and stores them compacted.
+type jsonCodec struct{}
+
+func (jsonCodec) Decode(b []byte) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", "  "); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (jsonCodec) Encode(s string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(s)); err != nil {
+		return nil, fmt.Errorf("value is not valid JSON: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+type hexCodec struct{}
+
+func (hexCodec) Decode(b []byte) (string, error) {
+	return hex.EncodeToString(b), nil
+}
+
+func (hexCodec) Encode(s string) ([]byte, error) {
+	return hex.DecodeString(strings.Join(strings.Fields(s), ""))
+}
+
+// hexdumpCodec shows values like hexdump -C. Only the hex columns are read
+// back, the offsets and the text column are ignored.
+type hexdumpCodec struct{}
+
+func (hexdumpCodec) Decode(b []byte) (string, error) {
+	return hex.Dump(b), nil
+}
+
+func (hexdumpCodec) Encode(s string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	lines := bufio.NewScanner(strings.NewReader(s))
+	for lines.Scan() {
+		fields := strings.Fields(lines.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "|") {
+				break
+			}
+
+			b, err := hex.DecodeString(field)
+			if err != nil || len(b) != 1 {
+				return nil, fmt.Errorf("invalid byte %q in hex dump", field)
+			}
+			buf.WriteByte(b[0])
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+type base64Codec struct{}
+
+func (base64Codec) Decode(b []byte) (string, error) {
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func (base64Codec) Encode(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+}
+
+type cborCodec struct{}
+
+func (cborCodec) Decode(b []byte) (string, error) {
+	var v interface{}
+	if err := cbor.Unmarshal(b, &v); err != nil {
+		return "", err
+	}
+	return marshalGeneric(v)
+}
+
+func (cborCodec) Encode(s string) ([]byte, error) {
+	v, err := unmarshalGeneric(s)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(v)
+}
+
+// bsonCodec shows documents as MongoDB extended JSON, which keeps BSON
+// types such as ObjectIds, dates and 64 bit integers intact. Fields are
+// shown, and stored back, sorted by name.
+type bsonCodec struct{}
+
+func (bsonCodec) Decode(b []byte) (string, error) {
+	var doc bson.M
+	if err := bson.Unmarshal(b, &doc); err != nil {
+		return "", err
+	}
+
+	js, err := bson.MarshalJSON(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(js)), nil
+}
+
+func (bsonCodec) Encode(s string) ([]byte, error) {
+	var doc bson.M
+	if err := bson.UnmarshalJSON([]byte(s), &doc); err != nil {
+		return nil, fmt.Errorf("value is not a valid extended JSON document: %v", err)
+	}
+	return bson.Marshal(sortedDoc(doc))
+}
+
+// sortedDoc orders the fields of m, and of documents nested in it, by name
+// so that it marshals the same way every time.
+func sortedDoc(m map[string]interface{}) bson.D {
+	doc := make(bson.D, 0, len(m))
+	for name, v := range m {
+		doc = append(doc, bson.DocElem{Name: name, Value: sortedValue(v)})
+	}
+	sort.Slice(doc, func(i, j int) bool { return doc[i].Name < doc[j].Name })
+	return doc
+}
+
+func sortedValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case bson.M:
+		return sortedDoc(v)
+	case map[string]interface{}:
+		return sortedDoc(v)
+	case []interface{}:
+		for i, e := range v {
+			v[i] = sortedValue(e)
+		}
+	}
+	return v
+}
+
+// intCodec stores an integer in size bytes, as written by encoding/binary.
+type intCodec struct {
+	size   int
+	order  binary.ByteOrder
+	signed bool
+}
+
+func (c intCodec) Decode(b []byte) (string, error) {
+	if len(b) != c.size {
+		return "", fmt.Errorf("value is %d bytes long, want %d", len(b), c.size)
+	}
+
+	var u uint64
+	switch c.size {
+	case 2:
+		u = uint64(c.order.Uint16(b))
+	case 4:
+		u = uint64(c.order.Uint32(b))
+	case 8:
+		u = c.order.Uint64(b)
+	}
+
+	if c.signed {
+		// sign extend from size bytes
+		shift := uint(64 - 8*c.size)
+		return strconv.FormatInt(int64(u<<shift)>>shift, 10), nil
+	}
+	return strconv.FormatUint(u, 10), nil
+}
+
+func (c intCodec) Encode(s string) ([]byte, error) {
+	var (
+		u   uint64
+		err error
+	)
+	if c.signed {
+		var i int64
+		i, err = strconv.ParseInt(strings.TrimSpace(s), 10, 8*c.size)
+		u = uint64(i)
+	} else {
+		u, err = strconv.ParseUint(strings.TrimSpace(s), 10, 8*c.size)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 8)
+	switch c.size {
+	case 2:
+		c.order.PutUint16(b, uint16(u))
+	case 4:
+		c.order.PutUint32(b, uint32(u))
+	case 8:
+		c.order.PutUint64(b, u)
+	}
+	return b[:c.size], nil
+}
+
+// varintCodec stores an integer as a (zig-zag encoded, if signed) varint.
+type varintCodec struct {
+	signed bool
+}
+
+func (c varintCodec) Decode(b []byte) (string, error) {
+	if c.signed {
+		i, n := binary.Varint(b)
+		if n <= 0 || n != len(b) {
+			return "", errors.New("value is not a single varint")
+		}
+		return strconv.FormatInt(i, 10), nil
+	}
+
+	u, n := binary.Uvarint(b)
+	if n <= 0 || n != len(b) {
+		return "", errors.New("value is not a single uvarint")
+	}
+	return strconv.FormatUint(u, 10), nil
+}
+
+func (c varintCodec) Encode(s string) ([]byte, error) {
+	b := make([]byte, binary.MaxVarintLen64)
+
+	if c.signed {
+		i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return b[:binary.PutVarint(b, i)], nil
+	}
+
+	u, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return b[:binary.PutUvarint(b, u)], nil
+}
+
+// timeCodec stores a Unix timestamp as a big endian integer of size bytes,
+// counting unit since the epoch. Times are shown as RFC 3339 in UTC.
+type timeCodec struct {
+	size int
+	unit time.Duration
+}
+
+func (c timeCodec) Decode(b []byte) (string, error) {
+	if len(b) != c.size {
+		return "", fmt.Errorf("value is %d bytes long, want %d", len(b), c.size)
+	}
+
+	var n int64
+	if c.size == 4 {
+		n = int64(binary.BigEndian.Uint32(b))
+	} else {
+		n = int64(binary.BigEndian.Uint64(b))
+	}
+	sec, frac := n/int64(time.Second/c.unit), n%int64(time.Second/c.unit)
+	return time.Unix(sec, frac*int64(c.unit)).UTC().Format(time.RFC3339Nano), nil
+}
+
+func (c timeCodec) Encode(s string) ([]byte, error) {
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 8)
+	if c.size == 4 {
+		binary.BigEndian.PutUint32(b, uint32(t.Unix()))
+	} else {
+		n := t.Unix()*int64(time.Second/c.unit) + int64(t.Nanosecond())/int64(c.unit)
+		binary.BigEndian.PutUint64(b, uint64(n))
+	}
+	return b[:c.size], nil
+}
+
+// uuidCodec shows 16 byte values in the canonical UUID form.
+type uuidCodec struct{}
+
+func (uuidCodec) Decode(b []byte) (string, error) {
+	if len(b) != 16 {
+		return "", fmt.Errorf("value is %d bytes long, want 16", len(b))
+	}
+	h := hex.EncodeToString(b)
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
+
+func (uuidCodec) Encode(s string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.Replace(strings.TrimSpace(s), "-", "", -1))
+	if err != nil || len(b) != 16 {
+		return nil, fmt.Errorf("%q is not a UUID", s)
+	}
+	return b, nil
+}
+
+// fixedWidth is implemented by codecs whose values all have the same size,
+// which lets them be followed by other parts of a tuple.
+type fixedWidth interface {
+	width() int
+}
+
+func (c intCodec) width() int  { return c.size }
+func (c timeCodec) width() int { return c.size }
+func (uuidCodec) width() int   { return 16 }
+
+const tuplePrefix = "tuple:"
+
+// tupleCodec handles composite keys made of the parts of its codecs, e.g.
+// "tuple:uint32be,unixnano64be,text" for a tenant id followed by a
+// timestamp and a name. All parts but the last must have a fixed width. In
+// the UI the parts are separated by "|", spaces around them are ignored;
+// fewer parts than codecs encode a prefix, as used by range bounds.
+type tupleCodec []Codec
+
+func parseTuple(spec string) (tupleCodec, error) {
+	var t tupleCodec
+	names := strings.Split(spec, ",")
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if strings.HasPrefix(name, tuplePrefix) {
+			return nil, errors.New("tuples cannot be nested")
+		}
+
+		c, err := lookupCodec(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := c.(fixedWidth); !ok && i < len(names)-1 {
+			return nil, fmt.Errorf("codec %q has no fixed width and must be the last part of a tuple", name)
+		}
+		t = append(t, c)
+	}
+	return t, nil
+}
+
+func (t tupleCodec) Decode(b []byte) (string, error) {
+	parts := make([]string, len(t))
+	for i, c := range t {
+		part := b
+		if f, ok := c.(fixedWidth); ok && i < len(t)-1 {
+			if len(b) < f.width() {
+				return "", fmt.Errorf("value is too short for part %d of the tuple", i+1)
+			}
+			part = b[:f.width()]
+		}
+
+		s, err := c.Decode(part)
+		if err != nil {
+			return "", fmt.Errorf("part %d of the tuple: %v", i+1, err)
+		}
+		parts[i] = s
+		b = b[len(part):]
+	}
+	return strings.Join(parts, " | "), nil
+}
+
+func (t tupleCodec) Encode(s string) ([]byte, error) {
+	var buf []byte
+	for i, part := range strings.SplitN(s, "|", len(t)) {
+		b, err := t[i].Encode(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("part %d of the tuple: %v", i+1, err)
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+// marshalGeneric renders a value decoded by a schemaless format as JSON.
+func marshalGeneric(v interface{}) (string, error) {
+	b, err := json.Marshal(stringKeys(v))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// unmarshalGeneric parses JSON for a schemaless format. Integral numbers
+// become int64 (or uint64 when too large), so they are not stored as floats.
+func unmarshalGeneric(s string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("value is not valid JSON: %v", err)
+	}
+	return convertNumbers(v), nil
+}
+
+// stringKeys converts maps with arbitrary keys, anywhere in v, to maps with
+// string keys that encoding/json can marshal.
+func stringKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = stringKeys(e)
+		}
+		return m
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = stringKeys(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = stringKeys(e)
+		}
+	}
+	return v
+}
+
+func convertNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return u
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = convertNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = convertNumbers(e)
+		}
+	}
+	return v
+}
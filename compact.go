@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/boltdb/bolt"
+)
+
+// compactTxSize is the number of key and value bytes copied per
+// transaction into the compacted file, which bounds the memory used.
+const compactTxSize = 64 << 20
+
+// CompactResult reports the file sizes before and after a compaction.
+type CompactResult struct {
+	Path     string `json:"path"`
+	Before   int64  `json:"before"`
+	After    int64  `json:"after"`
+	Replaced bool   `json:"replaced"`
+}
+
+// compactor copies buckets into a fresh file, committing every
+// compactTxSize bytes.
+type compactor struct {
+	dst  *bolt.DB
+	tx   *bolt.Tx
+	size int
+	fill float64
+}
+
+// bucket returns the bucket at path in the current transaction, with the
+// fill percent of the compaction.
+func (c *compactor) bucket(path BucketPath) (*bolt.Bucket, error) {
+	buck, err := path.bucket(c.tx)
+	if err != nil {
+		return nil, err
+	}
+	buck.FillPercent = c.fill
+	return buck, nil
+}
+
+func (c *compactor) begin() error {
+	var err error
+	c.tx, err = c.dst.Begin(true)
+	c.size = 0
+	return err
+}
+
+func (c *compactor) commit() error {
+	tx := c.tx
+	c.tx = nil
+	return tx.Commit()
+}
+
+func (c *compactor) copyBucket(src *bolt.Bucket, path BucketPath) error {
+	parent, name := path.split()
+
+	var buck *bolt.Bucket
+	var err error
+	if len(parent) == 0 {
+		buck, err = c.tx.CreateBucket(name)
+	} else if buck, err = c.bucket(parent); err == nil {
+		buck, err = buck.CreateBucket(name)
+	}
+	if err != nil {
+		return err
+	}
+	if err := buck.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	return src.ForEach(func(k, v []byte) error {
+		if v == nil {
+			return c.copyBucket(src.Bucket(k), path.child(k))
+		}
+
+		if c.size+len(k)+len(v) > compactTxSize {
+			if err := c.commit(); err != nil {
+				return err
+			}
+			if err := c.begin(); err != nil {
+				return err
+			}
+		}
+		c.size += len(k) + len(v)
+
+		buck, err := c.bucket(path)
+		if err != nil {
+			return err
+		}
+		return buck.Put(k, v)
+	})
+}
+
+// compactInto copies everything in db, within the read transaction tx, to
+// a new file at path and verifies the copy.
+func compactInto(tx *bolt.Tx, path string, fill float64) error {
+	dst, err := bolt.Open(path, 0600, &bolt.Options{Timeout: *timeout})
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	c := &compactor{dst: dst, fill: fill}
+	if err := c.begin(); err != nil {
+		return err
+	}
+	err = tx.ForEach(func(name []byte, buck *bolt.Bucket) error {
+		return c.copyBucket(buck, BucketPath{name})
+	})
+	if err != nil {
+		c.tx.Rollback()
+		return err
+	}
+	if err := c.commit(); err != nil {
+		return err
+	}
+
+	want := digest(tx)
+	return dst.View(func(tx *bolt.Tx) error {
+		if !bytes.Equal(digest(tx), want) {
+			return errors.New("compacted copy differs from the original")
+		}
+		return nil
+	})
+}
+
+// digest hashes every bucket name, sequence, key and value of tx.
+func digest(tx *bolt.Tx) []byte {
+	h := sha256.New()
+	tx.ForEach(func(name []byte, buck *bolt.Bucket) error {
+		digestBucket(h, name, buck)
+		return nil
+	})
+	return h.Sum(nil)
+}
+
+func digestBucket(h hash.Hash, name []byte, buck *bolt.Bucket) {
+	var n [8]byte
+	field := func(b []byte) {
+		binary.BigEndian.PutUint64(n[:], uint64(len(b)))
+		h.Write(n[:])
+		h.Write(b)
+	}
+
+	h.Write([]byte{'b'})
+	field(name)
+	binary.BigEndian.PutUint64(n[:], buck.Sequence())
+	h.Write(n[:])
+
+	buck.ForEach(func(k, v []byte) error {
+		if v == nil {
+			digestBucket(h, k, buck.Bucket(k))
+			return nil
+		}
+		h.Write([]byte{'k'})
+		field(k)
+		field(v)
+		return nil
+	})
+	h.Write([]byte{'e'})
+}
+
+// compact copies db into a fresh file at path, or, with replace, into a
+// temporary file that then replaces the database file and becomes db. The
+// caller must keep db from being used while it is replaced.
+func compact(path string, fill float64, replace bool) (CompactResult, error) {
+	if fill < 0.1 || fill > 1 {
+		return CompactResult{}, errors.New("fill percent must be between 0.1 and 1")
+	}
+
+	dst := path
+	if replace {
+		dst = *dbpath + ".compacting"
+		os.Remove(dst)
+	} else if _, err := os.Stat(dst); err == nil {
+		return CompactResult{}, fmt.Errorf("%s exists", dst)
+	}
+
+	res := CompactResult{Path: dst, Replaced: replace}
+	fi, err := os.Stat(*dbpath)
+	if err != nil {
+		return res, err
+	}
+	res.Before = fi.Size()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		return compactInto(tx, dst, fill)
+	})
+	if err != nil {
+		os.Remove(dst)
+		return res, err
+	}
+
+	if fi, err = os.Stat(dst); err != nil {
+		return res, err
+	}
+	res.After = fi.Size()
+
+	if !replace {
+		return res, nil
+	}
+
+	// the lock is held on a file, not its name: the copy is opened, and
+	// locked, before it takes the name of the database, and the old file
+	// is only closed once it did
+	compacted, err := bolt.Open(dst, 0600, &bolt.Options{Timeout: *timeout})
+	if err != nil {
+		os.Remove(dst)
+		return res, fmt.Errorf("opening the compacted db: %v", err)
+	}
+	if err := os.Rename(dst, *dbpath); err != nil {
+		compacted.Close()
+		os.Remove(dst)
+		return res, err
+	}
+	res.Path = *dbpath
+	// the copy already took the name, so a failing close of the old file
+	// does not undo the replace, but should not go unnoticed either
+	if err := db.Close(); err != nil {
+		log.Printf("closing the replaced db: %v", err)
+	}
+	db = compacted
+	return res, nil
+}
+
+func compactHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("compact takes a POST"))
+		return
+	}
+	r.ParseForm()
+
+	fill := bolt.DefaultFillPercent
+	if s := r.FormValue("fill"); s != "" {
+		var err error
+		if fill, err = strconv.ParseFloat(s, 64); err != nil || fill < 0.1 || fill > 1 {
+			writeError(w, http.StatusBadRequest, errors.New("fill percent must be between 0.1 and 1"))
+			return
+		}
+	}
+
+	// copies go next to the database, by their file name
+	replace := r.FormValue("replace") == "true"
+	path := *dbpath + ".compact"
+	if name := r.FormValue("path"); name != "" {
+		if name != filepath.Base(name) || name == "." || name == ".." {
+			writeError(w, http.StatusBadRequest, errors.New("path must be a file name, the copy is written next to the database"))
+			return
+		}
+		path = filepath.Join(filepath.Dir(*dbpath), name)
+	}
+
+	if replace {
+		dbLock.Lock()
+		defer dbLock.Unlock()
+	} else {
+		dbLock.RLock()
+		defer dbLock.RUnlock()
+	}
+
+	res, err := compact(path, fill, replace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, res)
+}
+
+// compactMain runs the compact subcommand:
+//
+//	BoltGUI compact -path my.db [-fill 0.5] (-o compacted.db | -replace)
+func compactMain(args []string) {
+	fs := subcommand("compact")
+	out := fs.String("o", "", "File to write the compacted copy to.")
+	fill := fs.Float64("fill", bolt.DefaultFillPercent, "Fill percent of the pages written, from 0.1 to 1; 1 packs them for read-mostly dbs.")
+	replace := fs.Bool("replace", false, "Replace the db with the compacted copy once it is verified.")
+	fs.Parse(args)
+	setup(fs)
+
+	if (*out == "") == !*replace {
+		fmt.Println("Give either -o or -replace")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// a write lock keeps other processes out while the file is replaced
+	openDB(!*replace)
+
+	res, err := compact(*out, *fill, *replace)
+	if cerr := db.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s: %d -> %d bytes\n", res.Path, res.Before, res.After)
+}
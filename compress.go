@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compressor is a compression stage that can be stacked under a codec,
+// e.g. "gzip+msgpack".
+type Compressor interface {
+	Compress(b []byte) ([]byte, error)
+	Decompress(b []byte) ([]byte, error)
+}
+
+// compressors holds every compressor by the name it is stacked with.
+var compressors = map[string]Compressor{
+	"gzip":         gzipCompressor{},
+	"zlib":         zlibCompressor{},
+	"deflate":      deflateCompressor{},
+	"snappy":       snappyCompressor{},
+	"snappystream": snappyStreamCompressor{},
+	"zstd":         zstdCompressor{},
+	"lz4":          lz4Compressor{},
+}
+
+// maxDecompressed bounds the size of decompressed values, so that a
+// corrupt or malicious value cannot exhaust memory.
+const maxDecompressed = 64 << 20
+
+var errTooLarge = fmt.Errorf("decompressed value is larger than %d bytes", maxDecompressed)
+
+// readAll reads r to the end, up to maxDecompressed bytes.
+func readAll(r io.Reader) ([]byte, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(r, maxDecompressed+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxDecompressed {
+		return nil, errTooLarge
+	}
+	return b, nil
+}
+
+// compressedCodec decompresses values with its compressors, outermost
+// first, and decodes the result with its codec; encoding goes the other
+// way.
+type compressedCodec struct {
+	layers []Compressor
+	codec  Codec
+}
+
+// parseCompressed returns the codec for a name like "zstd+json".
+func parseCompressed(name string) (Codec, error) {
+	names := strings.Split(name, "+")
+
+	var c compressedCodec
+	for _, layer := range names[:len(names)-1] {
+		comp, ok := compressors[layer]
+		if !ok {
+			return nil, fmt.Errorf("unknown compressor %q, available: %s", layer, strings.Join(compressorNames(), ", "))
+		}
+		c.layers = append(c.layers, comp)
+	}
+
+	codec, err := lookupCodec(names[len(names)-1])
+	if err != nil {
+		return nil, err
+	}
+	c.codec = codec
+	return c, nil
+}
+
+// compressorNames returns the names of all compressors, sorted.
+func compressorNames() []string {
+	names := make([]string, 0, len(compressors))
+	for name := range compressors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c compressedCodec) decompress(b []byte) ([]byte, error) {
+	for _, layer := range c.layers {
+		var err error
+		if b, err = layer.Decompress(b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (c compressedCodec) Decode(b []byte) (string, error) {
+	b, err := c.decompress(b)
+	if err != nil {
+		return "", err
+	}
+	return c.codec.Decode(b)
+}
+
+func (c compressedCodec) Encode(s string) ([]byte, error) {
+	b, err := c.codec.Encode(s)
+	if err != nil {
+		return nil, err
+	}
+	return c.compress(b)
+}
+
+func (c compressedCodec) compress(b []byte) ([]byte, error) {
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		var err error
+		if b, err = c.layers[i].Compress(b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// compressWith compresses b with the writer w returns.
+func compressWith(b []byte, w func(io.Writer) (io.WriteCloser, error)) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := w(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) Compress(b []byte) ([]byte, error) {
+	return compressWith(b, func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	})
+}
+
+func (gzipCompressor) Decompress(b []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	return readAll(r)
+}
+
+type zlibCompressor struct{}
+
+func (zlibCompressor) Compress(b []byte) ([]byte, error) {
+	return compressWith(b, func(w io.Writer) (io.WriteCloser, error) {
+		return zlib.NewWriter(w), nil
+	})
+}
+
+func (zlibCompressor) Decompress(b []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	return readAll(r)
+}
+
+// deflateCompressor handles raw deflate data, without a gzip or zlib
+// header.
+type deflateCompressor struct{}
+
+func (deflateCompressor) Compress(b []byte) ([]byte, error) {
+	return compressWith(b, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, flate.DefaultCompression)
+	})
+}
+
+func (deflateCompressor) Decompress(b []byte) ([]byte, error) {
+	return readAll(flate.NewReader(bytes.NewReader(b)))
+}
+
+// snappyCompressor handles the snappy block format, as written by
+// snappy.Encode.
+type snappyCompressor struct{}
+
+func (snappyCompressor) Compress(b []byte) ([]byte, error) {
+	return snappy.Encode(nil, b), nil
+}
+
+func (snappyCompressor) Decompress(b []byte) ([]byte, error) {
+	n, err := snappy.DecodedLen(b)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxDecompressed {
+		return nil, errTooLarge
+	}
+	return snappy.Decode(nil, b)
+}
+
+// snappyStreamCompressor handles the snappy framing format, as written by
+// snappy.NewBufferedWriter.
+type snappyStreamCompressor struct{}
+
+func (snappyStreamCompressor) Compress(b []byte) ([]byte, error) {
+	return compressWith(b, func(w io.Writer) (io.WriteCloser, error) {
+		return snappy.NewBufferedWriter(w), nil
+	})
+}
+
+func (snappyStreamCompressor) Decompress(b []byte) ([]byte, error) {
+	return readAll(snappy.NewReader(bytes.NewReader(b)))
+}
+
+// The zstd encoder and decoder are safe for concurrent use when used for
+// whole buffers, and expensive to create.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressed))
+)
+
+type zstdCompressor struct{}
+
+func (zstdCompressor) Compress(b []byte) ([]byte, error) {
+	return zstdEncoder.EncodeAll(b, nil), nil
+}
+
+func (zstdCompressor) Decompress(b []byte) ([]byte, error) {
+	return zstdDecoder.DecodeAll(b, nil)
+}
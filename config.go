@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// CodecRule selects the codecs of the buckets whose path matches Pattern.
+// Pattern is a path of path.Match globs, e.g. "users/*", each matched against
+// one bucket name as shown in the UI. Empty codec names leave the defaults in
+// place.
+type CodecRule struct {
+	Pattern string `json:"pattern"`
+	Key     string `json:"key,omitempty"`
+	Value   string `json:"value,omitempty"`
+}
+
+// Config is what BoltGUI remembers about a database between sessions.
+type Config struct {
+	Rules []CodecRule `json:"rules"`
+}
+
+var (
+	configMu sync.RWMutex
+	config   Config
+)
+
+// configFile returns the file the configuration is kept in.
+func configFile() string {
+	if *configPath != "" {
+		return *configPath
+	}
+	return *dbpath + ".boltgui.json"
+}
+
+// loadConfig reads the configuration file. A missing file is an empty
+// configuration.
+func loadConfig() error {
+	b, err := ioutil.ReadFile(configFile())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var c Config
+	if err := json.Unmarshal(b, &c); err != nil {
+		return fmt.Errorf("%s: %v", configFile(), err)
+	}
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("%s: %v", configFile(), err)
+	}
+
+	configMu.Lock()
+	config = c
+	configMu.Unlock()
+	return nil
+}
+
+// saveConfig writes the validated c to the configuration file and makes it
+// the current configuration. With -readonly nothing is written next to the
+// db and c only lasts until the server exits.
+func saveConfig(c Config) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	if !*readonly {
+		if err := ioutil.WriteFile(configFile(), b, 0644); err != nil {
+			return err
+		}
+	}
+	config = c
+	return nil
+}
+
+func (c Config) validate() error {
+	for _, rule := range c.Rules {
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return fmt.Errorf("pattern %q: %v", rule.Pattern, err)
+		}
+
+		for _, name := range []string{rule.Key, rule.Value} {
+			if name == "" {
+				continue
+			}
+			if _, err := lookupCodec(name); err != nil {
+				return fmt.Errorf("pattern %q: %v", rule.Pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether the bucket at p matches the rule's pattern. A
+// slash in a name is matched like any other character, as the pattern's
+// slashes only separate the names.
+func (rule CodecRule) matches(p BucketPath) bool {
+	globs := strings.Split(rule.Pattern, "/")
+	if len(globs) != len(p) {
+		return false
+	}
+	for i, glob := range globs {
+		name := strings.Replace(printable(p[i]), "/", "\uFFFD", -1)
+		if ok, _ := path.Match(glob, name); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// codecsFor returns the key and value codecs of the bucket at p: those of
+// the first rule matching it, falling back to printable keys and -coding.
+// A nil key codec means keys are shown as printable bytes.
+func codecsFor(p BucketPath) (key, value Codec, valueName string) {
+	keyName, valueName := "", *coding
+
+	configMu.RLock()
+	for _, rule := range config.Rules {
+		if !rule.matches(p) {
+			continue
+		}
+
+		if rule.Key != "" {
+			keyName = rule.Key
+		}
+		if rule.Value != "" {
+			valueName = rule.Value
+		}
+		break
+	}
+	configMu.RUnlock()
+
+	if keyName != "" {
+		key, _ = lookupCodec(keyName)
+	}
+	value, _ = lookupCodec(valueName)
+	return key, value, valueName
+}
+
+// ConfigInfo is the configuration together with what the UI needs to edit
+// it.
+type ConfigInfo struct {
+	Config
+	File   string   `json:"file"`
+	Codecs []string `json:"codecs"`
+	Coding string   `json:"coding"`
+}
+
+func getConfigHandler(w http.ResponseWriter, r *http.Request) {
+	configMu.RLock()
+	info := ConfigInfo{
+		Config: config,
+		File:   configFile(),
+		Codecs: codecNames(),
+		Coding: *coding,
+	}
+	configMu.RUnlock()
+
+	if info.Rules == nil {
+		info.Rules = []CodecRule{}
+	}
+	writeJSON(w, info)
+}
+
+func setConfigHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	var c Config
+	if err := json.Unmarshal([]byte(r.FormValue("config")), &c); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid config: %v", err))
+		return
+	}
+
+	if err := c.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := saveConfig(c); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	getConfigHandler(w, r)
+}
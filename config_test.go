@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+var ruleTests = []struct {
+	pattern string
+	path    BucketPath
+	match   bool
+}{
+	{"users", BucketPath{[]byte("users")}, true},
+	{"users", BucketPath{[]byte("users"), []byte("1")}, false},
+	{"users/*", BucketPath{[]byte("users"), []byte("1")}, true},
+	{"users/*", BucketPath{[]byte("users")}, false},
+	{"*/sessions", BucketPath{[]byte("eu"), []byte("sessions")}, true},
+	{"0x00*", BucketPath{[]byte{0, 1}}, true},
+
+	// slashes in names belong to the name, not the path
+	{"users/*", BucketPath{[]byte("users/1")}, false},
+	{"*", BucketPath{[]byte("users/1")}, true},
+	{"users/1", BucketPath{[]byte("users/1")}, false},
+	{"users/?", BucketPath{[]byte("users/1")}, false},
+}
+
+func TestRuleMatches(t *testing.T) {
+	for _, test := range ruleTests {
+		if got := (CodecRule{Pattern: test.pattern}).matches(test.path); got != test.match {
+			t.Errorf("%q matches %s = %v, want %v", test.pattern, test.path, got, test.match)
+		}
+	}
+}
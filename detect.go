@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+// Detection is a guess at the format of a value, with a confidence between
+// 0 and 1.
+type Detection struct {
+	Format     string  `json:"format"`
+	Confidence float64 `json:"confidence"`
+}
+
+// formatDetector is implemented by codecs that decide per value how to
+// decode it. decodeEntry reports their guess next to the value, and the UI
+// sends it back with edits so that they are stored in the same format.
+type formatDetector interface {
+	Detect(b []byte) Detection
+}
+
+// autoCodec sniffs every value and decodes it with the codec of the
+// format it most likely is. Formats without a codec are shown as a hex
+// dump. Edited values are stored as text unless the client names a format.
+type autoCodec struct{}
+
+func init() {
+	registerCodec("auto", autoCodec{})
+}
+
+func (c autoCodec) Decode(b []byte) (string, error) {
+	return formatCodec(c.Detect(b).Format).Decode(b)
+}
+
+func (autoCodec) Encode(s string) ([]byte, error) {
+	return []byte(s), nil
+}
+
+func (autoCodec) Detect(b []byte) Detection {
+	return detect(b)
+}
+
+// formatCodec returns the codec of a detected format.
+func formatCodec(format string) Codec {
+	c, err := lookupCodec(format)
+	if err != nil {
+		return hexdumpCodec{}
+	}
+	return c
+}
+
+// detectors are tried in order on every value; the most confident one
+// wins, earlier ones on a tie.
+var detectors = []func(b []byte) Detection{
+	detectMagic,
+	detectJSON,
+	detectText,
+	detectMsgpack,
+	detectGob,
+	detectProtobuf,
+	detectInteger,
+	detectSnappy,
+}
+
+// detect guesses the format of b. Compressed data is decompressed and
+// guessed again, giving formats like "gzip+json".
+func detect(b []byte) Detection {
+	best := Detection{Format: "hexdump"}
+	for _, detector := range detectors {
+		if d := detector(b); d.Confidence > best.Confidence {
+			best = d
+		}
+	}
+
+	if comp, ok := compressors[best.Format]; ok {
+		raw, err := comp.Decompress(b)
+		if err != nil {
+			return best
+		}
+
+		inner := detect(raw)
+		best.Format += "+" + inner.Format
+		if inner.Confidence > 0 {
+			best.Confidence *= inner.Confidence
+		}
+	}
+	return best
+}
+
+// magics are the leading bytes of formats that announce themselves.
+var magics = []struct {
+	format string
+	magic  []byte
+}{
+	{"gzip", []byte{0x1f, 0x8b, 0x08}},
+	{"zstd", []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{"snappystream", []byte("\xff\x06\x00\x00sNaPpY")},
+	{"lz4", []byte{0x04, 0x22, 0x4d, 0x18}},
+	{"zlib", []byte{0x78, 0x01}},
+	{"zlib", []byte{0x78, 0x5e}},
+	{"zlib", []byte{0x78, 0x9c}},
+	{"zlib", []byte{0x78, 0xda}},
+}
+
+func detectMagic(b []byte) Detection {
+	for _, m := range magics {
+		if !bytes.HasPrefix(b, m.magic) {
+			continue
+		}
+		// a corrupt stream, or text that happens to start the same
+		if _, err := compressors[m.format].Decompress(b); err != nil {
+			return Detection{m.format, 0.3}
+		}
+		return Detection{m.format, 0.95}
+	}
+	return Detection{}
+}
+
+// detectSnappy recognises the snappy block format, which has no magic, by
+// decoding it.
+func detectSnappy(b []byte) Detection {
+	// snappy compresses at most about 1:21, longer lengths are garbage
+	if n, err := snappy.DecodedLen(b); err != nil || n == 0 || n > 24*len(b) {
+		return Detection{}
+	}
+	if _, err := (snappyCompressor{}).Decompress(b); err != nil {
+		return Detection{}
+	}
+	return Detection{"snappy", 0.45}
+}
+
+func detectJSON(b []byte) Detection {
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) == 0 || !json.Valid(trimmed) {
+		return Detection{}
+	}
+
+	switch trimmed[0] {
+	case '{', '[':
+		return Detection{"json", 0.9}
+	}
+	// bare numbers, strings and literals are more likely plain text
+	return Detection{"json", 0.5}
+}
+
+func detectText(b []byte) Detection {
+	if !isText(b) {
+		return Detection{}
+	}
+	return Detection{"text", 0.7}
+}
+
+func detectMsgpack(b []byte) Detection {
+	if len(b) == 0 {
+		return Detection{}
+	}
+
+	if _, err := (msgpackCodec{}).Decode(b); err != nil {
+		return Detection{}
+	}
+
+	switch c := b[0]; {
+	case c >= 0x80 && c <= 0x9f, c >= 0xdc && c <= 0xdf:
+		// maps and arrays rarely parse by accident
+		return Detection{"msgpack", 0.8}
+	case len(b) == 1:
+		// every byte below 0x80 is a valid positive fixint
+		return Detection{"msgpack", 0.2}
+	}
+	return Detection{"msgpack", 0.5}
+}
+
+// detectGob recognises a gob stream that starts with a type definition: a
+// sequence of length prefixed messages, the first holding a negative type
+// id.
+func detectGob(b []byte) Detection {
+	first := true
+	for len(b) > 0 {
+		n, rest, err := readGobUint(b)
+		if err != nil || n == 0 || n > uint64(len(rest)) {
+			return Detection{}
+		}
+
+		if first {
+			id, _, err := readGobUint(rest[:n])
+			if err != nil || id&1 == 0 {
+				return Detection{}
+			}
+			first = false
+		}
+		b = rest[n:]
+	}
+
+	if first {
+		return Detection{}
+	}
+	return Detection{"gob", 0.6}
+}
+
+// readGobUint reads an unsigned integer as encoded by encoding/gob: values
+// below 128 in one byte, others as a negated byte count followed by the
+// big endian bytes.
+func readGobUint(b []byte) (uint64, []byte, error) {
+	if len(b) == 0 {
+		return 0, nil, errors.New("gob: short buffer")
+	}
+	if b[0] < 0x80 {
+		return uint64(b[0]), b[1:], nil
+	}
+
+	n := 256 - int(b[0])
+	if n > 8 || len(b) < n+1 {
+		return 0, nil, errors.New("gob: invalid uint")
+	}
+
+	var u uint64
+	for _, c := range b[1 : n+1] {
+		u = u<<8 | uint64(c)
+	}
+	return u, b[n+1:], nil
+}
+
+// detectProtobuf checks whether b parses as protobuf wire format, which
+// many short binary values do by accident, hence the low confidence.
+func detectProtobuf(b []byte) Detection {
+	fields := 0
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 || tag>>3 == 0 || tag>>3 > 1<<29-1 {
+			return Detection{}
+		}
+		b = b[n:]
+
+		switch tag & 7 {
+		case 0: // varint
+			if _, n = binary.Uvarint(b); n <= 0 {
+				return Detection{}
+			}
+		case 1: // fixed64
+			n = 8
+		case 2: // length delimited
+			l, m := binary.Uvarint(b)
+			if m <= 0 || l > uint64(len(b)-m) {
+				return Detection{}
+			}
+			n = m + int(l)
+		case 5: // fixed32
+			n = 4
+		default: // groups are deprecated, treat as not protobuf
+			return Detection{}
+		}
+
+		if n > len(b) {
+			return Detection{}
+		}
+		b = b[n:]
+		fields++
+	}
+
+	if fields == 0 {
+		return Detection{}
+	}
+	if fields == 1 {
+		return Detection{"protowire", 0.3}
+	}
+	return Detection{"protowire", 0.55}
+}
+
+// plausible bounds timestamps are expected in.
+var (
+	plausibleFrom = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	plausibleTo   = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func plausibleTime(t time.Time) bool {
+	return t.After(plausibleFrom) && t.Before(plausibleTo)
+}
+
+// detectInteger guesses fixed width big endian integers, and timestamps
+// stored as such.
+func detectInteger(b []byte) Detection {
+	switch len(b) {
+	case 8:
+		n := int64(binary.BigEndian.Uint64(b))
+		switch {
+		case plausibleTime(time.Unix(0, n)):
+			return Detection{"unixnano64be", 0.65}
+		case plausibleTime(time.Unix(0, n*int64(time.Millisecond))):
+			return Detection{"unixmilli64be", 0.6}
+		case plausibleTime(time.Unix(n, 0)):
+			return Detection{"unix64be", 0.6}
+		case b[0] == 0 && b[1] == 0:
+			// small counters and NextSequence ids
+			return Detection{"uint64be", 0.6}
+		}
+		return Detection{"uint64be", 0.3}
+	case 4:
+		n := binary.BigEndian.Uint32(b)
+		switch {
+		case plausibleTime(time.Unix(int64(n), 0)):
+			return Detection{"unix32be", 0.6}
+		case b[0] == 0:
+			return Detection{"uint32be", 0.55}
+		}
+		return Detection{"uint32be", 0.3}
+	}
+	return Detection{}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/boltdb/bolt"
+)
+
+// Record is an entry as exported and imported. Key and Value are rendered
+// by the codecs of the bucket; when the codecs cannot render them exactly
+// they hold the base64 encoded bytes instead, flagged by BinaryKey and
+// BinaryValue. Format is the format the auto codec detected. Bucket and
+// Path are left out in nested JSON, where the enclosing bucket gives them.
+type Record struct {
+	Bucket      BucketPath `json:"bucket,omitempty"`
+	Path        string     `json:"path,omitempty"`
+	Key         string     `json:"key"`
+	Value       string     `json:"value"`
+	Format      string     `json:"format,omitempty"`
+	BinaryKey   bool       `json:"binaryKey,omitempty"`
+	BinaryValue bool       `json:"binaryValue,omitempty"`
+}
+
+// newRecord renders the entry k, v of the bucket at path for export.
+func newRecord(path BucketPath, k, v []byte) Record {
+	keyCodec, _, _ := codecsFor(path)
+	entry := decodeEntry(path, k, v)
+
+	r := Record{Key: string(k), Value: entry.Value, Format: entry.Format}
+	switch {
+	case keyCodec != nil && roundTrips(keyCodec, k):
+		r.Key = entry.Key
+	case keyCodec != nil || !isText(k):
+		r.Key, r.BinaryKey = base64.StdEncoding.EncodeToString(k), true
+	}
+	if entry.Binary || entry.Lossy {
+		r.Value, r.BinaryValue = base64.StdEncoding.EncodeToString(v), true
+		r.Format = ""
+	}
+	return r
+}
+
+// roundTrips reports whether c decodes b to something it encodes back to b.
+func roundTrips(c Codec, b []byte) bool {
+	s, err := c.Decode(b)
+	if err != nil {
+		return false
+	}
+	e, err := c.Encode(s)
+	return err == nil && bytes.Equal(e, b)
+}
+
+// exportFormats are the formats export writes, by name.
+var exportFormats = map[string]func(w io.Writer, path BucketPath) error{
+	"json":  exportJSON,
+	"jsonl": exportJSONL,
+	"csv":   exportCSV,
+}
+
+// exportJSONL writes one JSON record per line.
+func exportJSONL(w io.Writer, path BucketPath) error {
+	enc := json.NewEncoder(w)
+	return walkPath(path, func(path BucketPath, k, v []byte) error {
+		r := newRecord(path, k, v)
+		r.Bucket, r.Path = path, path.String()
+		return enc.Encode(r)
+	})
+}
+
+var csvHeader = []string{"bucket", "path", "key", "value", "format", "binary_key", "binary_value"}
+
+// exportCSV writes a header line and then one line per entry. Buckets are
+// given as in JSON Lines: bucket holds the names as a JSON array of base64
+// strings, and path, for reading only, the path as shown in the UI.
+func exportCSV(w io.Writer, path BucketPath) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+
+	err := walkPath(path, func(path BucketPath, k, v []byte) error {
+		bucket, err := json.Marshal(path)
+		if err != nil {
+			return err
+		}
+		r := newRecord(path, k, v)
+		return cw.Write([]string{string(bucket), path.String(), r.Key, r.Value, r.Format, fmt.Sprint(r.BinaryKey), fmt.Sprint(r.BinaryValue)})
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportJSON writes a JSON array of the exported buckets, each an object
+// with its name, entries and nested buckets:
+//
+//	[{"name": "users", "entries": [{"key": "1", "value": "..."}], "buckets": [...]}]
+//
+// Binary names are base64 encoded and flagged by "binaryName".
+func exportJSON(w io.Writer, path BucketPath) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+
+	err := db.View(func(tx *bolt.Tx) error {
+		if len(path) > 0 {
+			buck, err := path.bucket(tx)
+			if err != nil {
+				return err
+			}
+			return writeJSONBucket(bw, buck, path)
+		}
+
+		first := true
+		return tx.ForEach(func(name []byte, buck *bolt.Bucket) error {
+			if !first {
+				bw.WriteByte(',')
+			}
+			first = false
+			return writeJSONBucket(bw, buck, BucketPath{name})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	bw.WriteString("]\n")
+	return bw.Flush()
+}
+
+// writeJSONBucket writes the bucket at path as exportJSON describes. Keys
+// are read twice, entries first and nested buckets second, so that nothing
+// is held in memory.
+func writeJSONBucket(w *bufio.Writer, buck *bolt.Bucket, path BucketPath) error {
+	name := path[len(path)-1]
+	if isText(name) {
+		fmt.Fprintf(w, `{"name":%s`, quote(string(name)))
+	} else {
+		fmt.Fprintf(w, `{"name":%q,"binaryName":true`, base64.StdEncoding.EncodeToString(name))
+	}
+
+	w.WriteString(`,"entries":[`)
+	first := true
+	err := buck.ForEach(func(k, v []byte) error {
+		if v == nil {
+			return nil
+		}
+		if !first {
+			w.WriteByte(',')
+		}
+		first = false
+		js, err := json.Marshal(newRecord(path, k, v))
+		w.Write(js)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	w.WriteString(`],"buckets":[`)
+	first = true
+	err = buck.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return nil
+		}
+		if !first {
+			w.WriteByte(',')
+		}
+		first = false
+		return writeJSONBucket(w, buck.Bucket(k), path.child(k))
+	})
+	if err != nil {
+		return err
+	}
+
+	w.WriteString("]}")
+	return nil
+}
+
+func exportHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	var (
+		path BucketPath
+		err  error
+	)
+	if b := r.FormValue("bucket"); b != "" {
+		path, err = parseBucketPath(b)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = "jsonl"
+	}
+	export, ok := exportFormats[format]
+	if !ok {
+		writeError(w, http.StatusBadRequest, errors.New("format must be json, jsonl or csv"))
+		return
+	}
+
+	// fail early on a missing bucket, while an error can still be reported
+	if len(path) > 0 {
+		err := db.View(func(tx *bolt.Tx) error {
+			_, err := path.bucket(tx)
+			return err
+		})
+		if err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(*dbpath), filepath.Ext(*dbpath))
+	for _, p := range path {
+		name += "-" + printable(p)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+	w.Header().Set("Content-Type", map[string]string{
+		"json":  "application/json",
+		"jsonl": "application/x-ndjson",
+		"csv":   "text/csv",
+	}[format])
+
+	if err := export(w, path); err != nil {
+		abortDownload("export", err)
+	}
+}
+
+// exportMain runs the export subcommand:
+//
+//	BoltGUI export -path my.db [-bucket users/sessions] [-format jsonl] [-o file]
+func exportMain(args []string) {
+	fs := subcommand("export")
+	format := fs.String("format", "jsonl", "Export format: json, jsonl or csv.")
+	bucket := fs.String("bucket", "", "Path of the bucket to export as shown in the UI, the whole db by default.")
+	out := fs.String("o", "", "File to write to, stdout by default.")
+	fs.Parse(args)
+	setup(fs)
+
+	export, ok := exportFormats[*format]
+	if !ok {
+		fmt.Println("-format must be json, jsonl or csv")
+		os.Exit(2)
+	}
+
+	var path BucketPath
+	if *bucket != "" {
+		var err error
+		if path, err = parseDisplayPath(*bucket); err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+	}
+
+	openDB(true)
+	defer db.Close()
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := export(w, path); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
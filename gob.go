@@ -0,0 +1,481 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerCodec("gob", gobCodec{})
+}
+
+// gobCodec dumps encoding/gob streams without the Go types that wrote them,
+// from the type definitions the stream carries: structs by their type and
+// field names, slices, arrays and maps by their elements, and the values of
+// types encoding themselves, such as time.Time, as their bytes. The dump is
+// read-only.
+type gobCodec struct{}
+
+func (gobCodec) Decode(b []byte) (string, error) {
+	d := &gobDecoder{types: gobMetaTypes(), stream: b}
+	var buf strings.Builder
+	for len(d.stream) > 0 {
+		v, err := d.message()
+		if err != nil {
+			return "", err
+		}
+		if v != nil {
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			dumpGob(&buf, v, "")
+		}
+	}
+	if buf.Len() == 0 {
+		return "", errors.New("gob: no values in stream")
+	}
+	return buf.String(), nil
+}
+
+func (gobCodec) Encode(s string) ([]byte, error) {
+	return nil, errors.New("gob dumps cannot be edited, edit the raw bytes")
+}
+
+// The ids gob predefines. gobEncoderType has no fixed id; it is only used
+// here, to describe wireType.
+const (
+	gobBool        = 1
+	gobInt         = 2
+	gobUint        = 3
+	gobFloat       = 4
+	gobBytes       = 5
+	gobString      = 6
+	gobComplex     = 7
+	gobInterface   = 8
+	gobWireType    = 16
+	gobArrayType   = 17
+	gobCommonType  = 18
+	gobSliceType   = 19
+	gobStructType  = 20
+	gobFieldType   = 21
+	gobFieldTypes  = 22
+	gobMapType     = 23
+	gobEncoderType = 24
+
+	gobFirstUserID = 64
+	gobMaxDepth    = 100
+)
+
+// gobType is a type definition from a gob stream, flattened from its
+// wireType.
+type gobType struct {
+	kind      string // array, slice, struct, map or encoder
+	name      string
+	elem, key int
+	len       int
+	fields    []gobFieldDef
+	text      bool // encoded by a TextMarshaler
+}
+
+type gobFieldDef struct {
+	name string
+	id   int
+}
+
+// gobMetaTypes returns the types describing type definitions, which every
+// stream knows without being told.
+func gobMetaTypes() map[int]*gobType {
+	common := gobFieldDef{"CommonType", gobCommonType}
+	return map[int]*gobType{
+		gobWireType: {kind: "struct", name: "wireType", fields: []gobFieldDef{
+			{"ArrayT", gobArrayType},
+			{"SliceT", gobSliceType},
+			{"StructT", gobStructType},
+			{"MapT", gobMapType},
+			{"GobEncoderT", gobEncoderType},
+			{"BinaryMarshalerT", gobEncoderType},
+			{"TextMarshalerT", gobEncoderType},
+		}},
+		gobArrayType:   {kind: "struct", name: "arrayType", fields: []gobFieldDef{common, {"Elem", gobInt}, {"Len", gobInt}}},
+		gobCommonType:  {kind: "struct", name: "CommonType", fields: []gobFieldDef{{"Name", gobString}, {"Id", gobInt}}},
+		gobSliceType:   {kind: "struct", name: "sliceType", fields: []gobFieldDef{common, {"Elem", gobInt}}},
+		gobStructType:  {kind: "struct", name: "structType", fields: []gobFieldDef{common, {"Field", gobFieldTypes}}},
+		gobFieldType:   {kind: "struct", name: "fieldType", fields: []gobFieldDef{{"Name", gobString}, {"Id", gobInt}}},
+		gobFieldTypes:  {kind: "slice", elem: gobFieldType},
+		gobMapType:     {kind: "struct", name: "mapType", fields: []gobFieldDef{common, {"Key", gobInt}, {"Elem", gobInt}}},
+		gobEncoderType: {kind: "struct", name: "gobEncoderType", fields: []gobFieldDef{common}},
+	}
+}
+
+// The values decoded from a stream. Builtin types decode to bool, int64,
+// uint64, float64, complex128, []byte and string, slices and arrays to
+// []interface{}.
+type (
+	gobStruct struct {
+		name   string
+		fields []gobField
+	}
+	gobField struct {
+		name  string
+		value interface{}
+	}
+	gobMap   [][2]interface{}
+	gobIface struct {
+		name  string // empty for nil
+		value interface{}
+	}
+	gobEncoded struct {
+		name string
+		text bool
+		data []byte
+	}
+)
+
+func (s gobStruct) field(name string) interface{} {
+	for _, f := range s.fields {
+		if f.name == name {
+			return f.value
+		}
+	}
+	return nil
+}
+
+// gobDecoder reads the messages of one stream, keeping the types defined
+// in it. b is the rest of the current message and stream what follows it.
+// Reads past an error return zero values; err holds the first.
+type gobDecoder struct {
+	types  map[int]*gobType
+	b      []byte
+	stream []byte
+	depth  int
+	err    error
+}
+
+func (d *gobDecoder) fail(format string, args ...interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf("gob: "+format, args...)
+	}
+	d.b, d.stream = nil, nil
+}
+
+func (d *gobDecoder) uint() uint64 {
+	u, rest, err := readGobUint(d.b)
+	if err != nil {
+		d.fail("%v", strings.TrimPrefix(err.Error(), "gob: "))
+		return 0
+	}
+	d.b = rest
+	return u
+}
+
+func (d *gobDecoder) int() int64 {
+	u := d.uint()
+	if u&1 != 0 {
+		return ^int64(u >> 1)
+	}
+	return int64(u >> 1)
+}
+
+func (d *gobDecoder) float() float64 {
+	// floats are sent byte reversed, so that small integers are short
+	u := d.uint()
+	var r uint64
+	for i := 0; i < 8; i++ {
+		r = r<<8 | u&0xff
+		u >>= 8
+	}
+	return math.Float64frombits(r)
+}
+
+func (d *gobDecoder) bytes() []byte {
+	n := d.uint()
+	if n > uint64(len(d.b)) {
+		d.fail("length %d exceeds the data", n)
+		return nil
+	}
+	b := append([]byte{}, d.b[:n]...)
+	d.b = d.b[n:]
+	return b
+}
+
+// count reads the number of elements of a slice, array or map, each of
+// which takes at least a byte.
+func (d *gobDecoder) count() int {
+	n := d.uint()
+	if n > uint64(len(d.b)) {
+		d.fail("count %d exceeds the data", n)
+		return 0
+	}
+	return int(n)
+}
+
+// next makes the next message of the stream the current one.
+func (d *gobDecoder) next() {
+	n, rest, err := readGobUint(d.stream)
+	if err != nil {
+		d.fail("%v", strings.TrimPrefix(err.Error(), "gob: "))
+		return
+	}
+	if n == 0 || n > uint64(len(rest)) {
+		d.fail("invalid message length")
+		return
+	}
+	d.b, d.stream = rest[:n], rest[n:]
+}
+
+// message decodes the next message and returns the value it holds, or nil
+// if it defines a type.
+func (d *gobDecoder) message() (interface{}, error) {
+	d.next()
+	var v interface{}
+	if id := d.int(); id < 0 {
+		d.define(-id)
+	} else {
+		v = d.value(int(id))
+	}
+	if d.err == nil && len(d.b) > 0 {
+		d.fail("%d bytes left in message", len(d.b))
+	}
+	return v, d.err
+}
+
+// define reads the definition of type id.
+func (d *gobDecoder) define(id int64) {
+	if id < gobFirstUserID || d.types[int(id)] != nil {
+		d.fail("invalid type id %d", id)
+		return
+	}
+	wire, _ := d.structValue(d.types[gobWireType]).(gobStruct)
+	if d.err != nil {
+		return
+	}
+
+	t := &gobType{}
+	var def gobStruct
+	for _, f := range wire.fields {
+		def, _ = f.value.(gobStruct)
+		switch f.name {
+		case "ArrayT":
+			t.kind = "array"
+		case "SliceT":
+			t.kind = "slice"
+		case "StructT":
+			t.kind = "struct"
+		case "MapT":
+			t.kind = "map"
+		case "GobEncoderT", "BinaryMarshalerT":
+			t.kind = "encoder"
+		case "TextMarshalerT":
+			t.kind, t.text = "encoder", true
+		}
+	}
+	if t.kind == "" {
+		d.fail("empty definition of type %d", id)
+		return
+	}
+
+	if common, ok := def.field("CommonType").(gobStruct); ok {
+		t.name, _ = common.field("Name").(string)
+	}
+	elem, _ := def.field("Elem").(int64)
+	key, _ := def.field("Key").(int64)
+	length, _ := def.field("Len").(int64)
+	t.elem, t.key, t.len = int(elem), int(key), int(length)
+	if fields, ok := def.field("Field").([]interface{}); ok {
+		for _, f := range fields {
+			f, _ := f.(gobStruct)
+			name, _ := f.field("Name").(string)
+			fid, _ := f.field("Id").(int64)
+			t.fields = append(t.fields, gobFieldDef{name, int(fid)})
+		}
+	}
+	d.types[int(id)] = t
+}
+
+// value reads a top-level value of type id: structs as they are, others
+// as the single field of a struct.
+func (d *gobDecoder) value(id int) interface{} {
+	if t := d.types[id]; t != nil && t.kind == "struct" {
+		return d.structValue(t)
+	}
+	if delta := d.uint(); delta != 0 {
+		d.fail("non-zero delta %d for singleton", delta)
+		return nil
+	}
+	return d.fieldValue(id)
+}
+
+func (d *gobDecoder) structValue(t *gobType) interface{} {
+	s := gobStruct{name: t.name}
+	field := -1
+	for d.err == nil {
+		delta := d.uint()
+		if delta == 0 {
+			break
+		}
+		if delta > uint64(len(t.fields)) || field+int(delta) >= len(t.fields) {
+			d.fail("field %d out of range in %s", field+int(delta), t.name)
+			break
+		}
+		field += int(delta)
+		f := t.fields[field]
+		s.fields = append(s.fields, gobField{f.name, d.fieldValue(f.id)})
+	}
+	return s
+}
+
+func (d *gobDecoder) fieldValue(id int) interface{} {
+	if d.depth++; d.depth > gobMaxDepth {
+		d.fail("nested too deeply")
+	}
+	defer func() { d.depth-- }()
+	if d.err != nil {
+		return nil
+	}
+
+	switch id {
+	case gobBool:
+		return d.uint() != 0
+	case gobInt:
+		return d.int()
+	case gobUint:
+		return d.uint()
+	case gobFloat:
+		return d.float()
+	case gobComplex:
+		re := d.float()
+		return complex(re, d.float())
+	case gobBytes:
+		return d.bytes()
+	case gobString:
+		return string(d.bytes())
+	case gobInterface:
+		return d.interfaceValue()
+	}
+
+	t := d.types[id]
+	if t == nil {
+		d.fail("undefined type id %d", id)
+		return nil
+	}
+	switch t.kind {
+	case "struct":
+		return d.structValue(t)
+	case "encoder":
+		return gobEncoded{t.name, t.text, d.bytes()}
+	case "map":
+		m := gobMap{}
+		for i, n := 0, d.count(); i < n && d.err == nil; i++ {
+			k := d.fieldValue(t.key)
+			m = append(m, [2]interface{}{k, d.fieldValue(t.elem)})
+		}
+		return m
+	default:
+		elems := []interface{}{}
+		for i, n := 0, d.count(); i < n && d.err == nil; i++ {
+			elems = append(elems, d.fieldValue(t.elem))
+		}
+		return elems
+	}
+}
+
+// interfaceValue reads the name of the concrete type, the definitions of
+// types it needs, its id and its value, delimited by a byte count.
+func (d *gobDecoder) interfaceValue() interface{} {
+	name := string(d.bytes())
+	if name == "" {
+		return gobIface{}
+	}
+
+	// the types the value needs are defined first, in messages of their
+	// own that cut the one holding the value short
+	for d.err == nil {
+		if len(d.b) == 0 {
+			d.next()
+		}
+		id := d.int()
+		if id >= 0 {
+			d.uint() // byte count of the value
+			return gobIface{name, d.value(int(id))}
+		}
+		d.define(-id)
+		if len(d.b) > 0 {
+			d.uint() // byte count of what follows
+		}
+	}
+	return nil
+}
+
+// dumpGob writes v as Go-like text, nested values indented below indent.
+func dumpGob(buf *strings.Builder, v interface{}, indent string) {
+	inner := indent + "  "
+	switch v := v.(type) {
+	case gobStruct:
+		if len(v.fields) == 0 {
+			fmt.Fprintf(buf, "%s{}", v.name)
+			return
+		}
+		fmt.Fprintf(buf, "%s{\n", v.name)
+		for _, f := range v.fields {
+			fmt.Fprintf(buf, "%s%s: ", inner, f.name)
+			dumpGob(buf, f.value, inner)
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for _, e := range v {
+			buf.WriteString(inner)
+			dumpGob(buf, e, inner)
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "]")
+	case gobMap:
+		if len(v) == 0 {
+			buf.WriteString("map[]")
+			return
+		}
+		buf.WriteString("map[\n")
+		for _, kv := range v {
+			buf.WriteString(inner)
+			dumpGob(buf, kv[0], inner)
+			buf.WriteString(": ")
+			dumpGob(buf, kv[1], inner)
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "]")
+	case gobIface:
+		if v.name == "" {
+			buf.WriteString("nil")
+			return
+		}
+		fmt.Fprintf(buf, "(%s) ", v.name)
+		dumpGob(buf, v.value, indent)
+	case gobEncoded:
+		if v.text {
+			fmt.Fprintf(buf, "%s(%s)", v.name, strconv.Quote(string(v.data)))
+		} else {
+			fmt.Fprintf(buf, "%s(0x%s)", v.name, hex.EncodeToString(v.data))
+		}
+	case string:
+		buf.WriteString(strconv.Quote(v))
+	case []byte:
+		if isText(v) {
+			fmt.Fprintf(buf, "[]byte(%s)", strconv.Quote(string(v)))
+		} else {
+			buf.WriteString("0x" + hex.EncodeToString(v))
+		}
+	case float64:
+		buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	default:
+		fmt.Fprint(buf, v)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type gobTestPoint struct{ X, Y int }
+
+type gobTestCircle struct {
+	Center gobTestPoint
+	R      float64
+}
+
+// gobTestPair is only used as an element, which gob does not name.
+type gobTestPair struct{ X, Y int }
+
+type gobTestDoc struct {
+	Name  string
+	Tags  []string
+	Attrs map[string]int
+	Shape interface{}
+	Pts   [2]gobTestPair
+	Raw   []byte
+	Text  []byte
+	U     uint64
+	I     int64
+	F     []float64
+	Ok    bool
+	C     complex128
+	Next  *gobTestDoc
+}
+
+type gobTestNode struct {
+	V    int
+	Next *gobTestNode
+}
+
+func init() {
+	gob.Register(gobTestCircle{})
+}
+
+// gobStream encodes vs with encoding/gob into one stream.
+func gobStream(t testing.TB, vs ...interface{}) []byte {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	for _, v := range vs {
+		if err := enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// gobList returns a list of n nodes.
+func gobList(n int) *gobTestNode {
+	var l *gobTestNode
+	for i := n; i > 0; i-- {
+		l = &gobTestNode{i, l}
+	}
+	return l
+}
+
+var gobTests = []struct {
+	name string
+	vs   []interface{}
+	dump string
+}{
+	{"int", []interface{}{42}, "42"},
+	{"string", []interface{}{"a\"b"}, `"a\"b"`},
+	{"map", []interface{}{map[string][]int{"k": {1, -1}}}, `map[
+  "k": [
+    1
+    -1
+  ]
+]`},
+	{"struct", []interface{}{gobTestCircle{gobTestPoint{1, 2}, 0.5}}, `gobTestCircle{
+  Center: gobTestPoint{
+    X: 1
+    Y: 2
+  }
+  R: 0.5
+}`},
+	{"values", []interface{}{gobTestPoint{1, 0}, gobTestPoint{}}, `gobTestPoint{
+  X: 1
+}
+gobTestPoint{}`},
+	// values in interfaces carry their registered name, elements none
+	{"nested", []interface{}{gobTestDoc{
+		Name:  "doc",
+		Tags:  []string{"a", "b"},
+		Attrs: map[string]int{"n": -1},
+		Shape: gobTestCircle{R: 2},
+		Pts:   [2]gobTestPair{{3, 4}, {-5, 6}},
+		Raw:   []byte{0, 0xff},
+		Text:  []byte("text"),
+		U:     math.MaxUint64,
+		I:     math.MinInt64,
+		F:     []float64{math.SmallestNonzeroFloat64, math.MaxFloat64, math.Inf(-1), math.NaN(), -2},
+		Ok:    true,
+		C:     complex(1, -1),
+		Next:  &gobTestDoc{Name: "next"},
+	}}, `gobTestDoc{
+  Name: "doc"
+  Tags: [
+    "a"
+    "b"
+  ]
+  Attrs: map[
+    "n": -1
+  ]
+  Shape: (PKG.gobTestCircle) gobTestCircle{
+    Center: gobTestPoint{}
+    R: 2
+  }
+  Pts: [
+    {
+      X: 3
+      Y: 4
+    }
+    {
+      X: -5
+      Y: 6
+    }
+  ]
+  Raw: 0x00ff
+  Text: []byte("text")
+  U: 18446744073709551615
+  I: -9223372036854775808
+  F: [
+    5e-324
+    1.7976931348623157e+308
+    -Inf
+    NaN
+    -2
+  ]
+  Ok: true
+  C: (1-1i)
+  Next: gobTestDoc{
+    Name: "next"
+    Pts: [
+      {}
+      {}
+    ]
+  }
+}`},
+}
+
+func TestGob(t *testing.T) {
+	for _, test := range gobTests {
+		got, err := gobCodec{}.Decode(gobStream(t, test.vs...))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		// registered names hold the import path of the package
+		want := strings.Replace(test.dump, "PKG", reflect.TypeOf(gobTestCircle{}).PkgPath(), -1)
+		if got != want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, got, want)
+		}
+	}
+}
+
+func TestGobTruncated(t *testing.T) {
+	b := gobStream(t, gobTests[len(gobTests)-1].vs...)
+	for i := 0; i < len(b); i++ {
+		if s, err := (gobCodec{}).Decode(b[:i]); err == nil {
+			t.Fatalf("%d of %d bytes decoded to %s", i, len(b), s)
+		}
+	}
+}
+
+func TestGobDepth(t *testing.T) {
+	if _, err := (gobCodec{}).Decode(gobStream(t, gobList(gobMaxDepth/2))); err != nil {
+		t.Errorf("%d nodes: %v", gobMaxDepth/2, err)
+	}
+	_, err := gobCodec{}.Decode(gobStream(t, gobList(2*gobMaxDepth)))
+	if err == nil || !strings.Contains(err.Error(), "nested too deeply") {
+		t.Errorf("%d nodes: err = %v, want nested too deeply", 2*gobMaxDepth, err)
+	}
+}
+
+// FuzzGob checks that no input makes the decoder panic or hang.
+func FuzzGob(f *testing.F) {
+	for _, test := range gobTests {
+		f.Add(gobStream(f, test.vs...))
+	}
+	f.Add(gobStream(f, gobList(2*gobMaxDepth)))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		gobCodec{}.Decode(b)
+	})
+}
@@ -188,82 +188,236 @@ var _escData = map[string]*_escFile{
 
 	"/html/css/main.css": {
 		local:   "html/css/main.css",
-		size:    250,
-		modtime: 1438699833,
+		size:    870,
+		modtime: 1792194430,
 		compressed: `
-H4sIAAAJbogA/2yOTU7EMAyF1+QU1khsRpoZURUByVXYpI0hlqykcqz+irsTWpbd+vP73rv3kkuxHX5l
-QYAN+pwUk1q4fDZvL+8XVy+cxYJgcPBjjIktbOYpUBnYLxYoMSV0pqJ7QL7VsCx/HxMFjRaa12HeqeKs
-XtDXEgNQfYVWtCOKUu/ZPa4TMcMgOFbFgSl9Q8xCax3lmZfrY4/+m9uP56O203TDmfR8VmxO778BAAD/
-/2AENo/6AAAA
+H4sIAAAAAAAC/3RSy27jOgxdx19B9OJuinGapknTyr8yG1qmY6GyaFDMG/33gWK14w6SrXh4XuLcCsdo
+ampZCOACloNSUAMPv5eb57eHCix7FgNCTQWfRVF0K7gUs8bFwePJgAveBaqKz6KYN+RLCiqnhDi4RjsD
+y/VwvE6VjopCCBcoAEAoujOZPYk6i756ejw472EQ2lPQcezCFjoWd+ag6P3p8em6mplX7/+PsrWGko5O
+b9vqlvfs1i7g6LXloGWLvfMnAz0HjgPajEqBHMUE6/FYduS2nRp4XSxSsBnvSVrPh/JkAHfK41LrvJKM
+O7J1oaxZlXsDz4tcxzwSiu3+QsYZLDIBS4969eYZ1YAk2eqbz1OrBtbfZO5MCZw/67/NZlPlWNea4W2d
+y6LjwKJT2cXEVJ4iXO4puT4hSqG48/oL5pb7Ae3Xw2RPefgRV1HjbdrR5yEXG1JyPy4FOpT1zn6Q3ri5
+svZsP/6tZFqwJ6uOw72Oo+KWGpjn65/Uh++r1WpZFbN0tGVDlgUTk4GrsHbCu233kwXb/ONfJC928/rS
+JNCfAQAimtMxZgMAAA==
 `,
 	},
 
 	"/html/index.html": {
 		local:   "html/index.html",
-		size:    2408,
-		modtime: 1445516845,
+		size:    16451,
+		modtime: 1792197227,
 		compressed: `
-H4sIAAAJbogA/4xWzW7jNhC++ym4RA/bg6g6bi+FLKANgqJosT31AShpItGmSJWkEguLvHuH1L/lGD4E
-Jocz33yc+ThK8qXQuesaIJWrZbpL/A9RZcSb5kh/19L98e+fNN0RklTAC7/ApRTqTAzII7Wuk2ArAEdJ
-ZeD1SCvnGvtrHNf8kheKZVo76wxv/CbXdTwZ4gM7sF/i3NrZxmqBXtbSu4k83yN1cHE+ekzsgWo+xe96
-BJsb0ThiTT5Ty3UB7PRfC6YLlPpltGf7PVLyFE6IkMR9bOpxPgfjJ35hpdalBN4IGwC9LZYiszFXZSu5
-Odl4z35mh3G/TbK7n+XRap6ui7lNssJH/1ZEU0jkGmmjn9j+wJ4eDDeQt8YKrUqQDZgHIjJUVdkKVNjG
-OYlHlSWZLrohvhBvJJfcWmyyVg57DGZQyFCzwQl16x2MlhIMyrfNz+Ds82Qi3JKsN/4trJswMJzjsfMA
-Bhrg7kh7g1DLABaMdlTg9+9hz/zu44MiR23RvAwIpt+819cfhCrg8iNNx7Dalh8fSRw2CybVUzoQx2o8
-LQ6y1jmtxkpkDqk5FRUoKbxZ00oZGVFWLljhIvCh+HpIkZ/XnPzZV+Txgr9J3KPOaYZSpruFJST3ANGb
-gPdllXrzdZmGNZ24hj0dfOZ9ww0ot2JHU+I5TclWPJIYqS2p7nZrFaDLqzb1mNevI6FwikAohm2zWqzz
-MV4Ufbl9SZa5hGpat5g1020qUYAiAXtQWwCvcarINbaC9x77G6+RQSN5DpWWxSxOovzJKu/Q5j5xz5he
-N70xouamo+mzwTbAUNhtM5PY00x3cxHHx7iYocjdQY3kHHIUxZFCIRzehkvmvwZ0o42BTHCJ/INdPMdZ
-xoe1nxNOwsZtfrgCR7iw3+B9uhRWj6A8TLdp+zbyyxD6gtTvBOGLOqT3BLW9n59DN2ijqy8eCpiv2a+7
-jBZyho5UYGCpErzbi2fJ8NCPwBHrfiL8K7SS3dWtH8K9C7zh/MZlC5+zDsef8X6gqK/4wbklmtsjblT7
-Yp7ps3+u//x1a37dQXrnRglVLpFyrnKQHu05rG5OxPWN1p+31fG0RJjwAUPF+X+s/g8AAP//pZJ83mgJ
-AAA=
+H4sIAAAAAAAC/7w8XZfbto7v+RWI9m5nph3L+ep9mHq0p02bPdn2pD1J2/tMS7DFmCZ1SWo87tT/fQ9I
+SpZkSbaTyX0ZSyQIgCAAAiA1s6eZSu22QMjtWiRPZvQDcjlhRXEb/aCE/d8/3kbJE4BZjiyjB4CZ4HIF
+GsVtZOxWoMkRbQS5xsVtlFtbmJvpdM3u00zGc6WssZoV9JKq9bRumL6MX8bfTlNj9m3xmss4NSYaJUT8
+3kYW7y2NrggTojWrxz/xGEyqeWHB6HTPWqoyjD/+u0S9dSz5x8nz+Pnz+KVj4aOJktnUj00AAIaRsY/s
+Pl4qtRTICm4cQmqbCj43UyaXpWD6o5k+j1/FL6v3QyJPxqmcKs2PXWEeEmnh/2imJZ/UQya2EGbyLH7+
+Mn5x4nCNaakNV3KJokB9woi5EnZZclYUB8CzaaVls7nKtmF8xu8gFcyY2yhV0jIuUQcNCTILQHI5IQCt
+hEB9G/1Qpiu05nXdBMzA3Df+wo2tcQDMmEBtCYHGApm9jXwDl80BsWs0lQY+PLj3mN52uwhSoQzeRs0B
+rul7grr8B5cZ3l9FSTVsbZa73WzqXhqc5C+SwPhsmr9odJiCyUoOgs1RgPs74XKhImKdL26jf2ilbKyR
+Zb9KsY0SepooKbazKY0/AV2GC1YK28FINpMS741X4v5EpBumJZfLDlJj2ZJak/BwgG5eWqtqhHMrYW7l
+JGNyiRqKUoiJ5svcula8557pVPB01V4G6ru8ipKf7rmdTT3W5uIfUPAyaJCovMzUSFaYXNko+VFtpFAs
+gzlLV2Uxm7IBQeB9oXQLWfKTb2JC1EMcH3I58XQeHtr8E/gfWlxefDRKXlztdlHyfx9+fdciegYGsUcB
+v3CJ5lMQpebOo3n94c/27LvruFB6XUmDnidcCi4RDDKd5m7VTDlfc9teNl3KDw6C1q7JHZdFaRv7QNRC
+HnyAQ7tWGYo2Vk80/ncEhWAp5kpk5C08pTYdgwJT+wnIuWwhApipwnIl4Y6JknyEsnmU/IxbA0xmvtXM
+ph5odOQKt8aPPAncY46SPwcpzKZ+lq02Z7VJS85pjulqru6PzFzjEu+jBN7T72zqEbVkeoob60Hsm6KE
+S3h4GOyPl2jfsTWay6v4o+Ly8mJKOtoWkWNBK+GcNfmCCCy3AislALxDvd3kqHHIo3iib+WlLIW4ipKv
+LF+j+S5oftcA9q7My9IrezTgdhzNjBs2F5j1C7mUzp0mnt9Dl9YlWE1zhCBfjJIakEPKZIpib6Wv3fsn
+M0QB3xg3ObcmFiiXNh/kSCDTDYbotcfnT8makyf7hozftdjNX3VUJGOWTaxaLqkxVUKwwmC1L/yX2xFN
+lLx2v7Np/qqFrRXDVEN5Rm9+XFtDG+AbFKLTDTArqiABNrkyCAWzOayZTXM0wOjVopZwifEyhhkRSUqD
+2ky/nrn4N7mC0iBwa8BzEHcoAPyeIyy4NtbjhQ2XJg7GE1boaTfceF8Koq8RVli48MnTbltsquSCL+MF
+F0gxhIOID22maa0D4Q3xSOsyZzQZA3W4cw26zUopLRdgcwSD+g41UExg4tpgiwMRW7K/ahXcS9TDndWH
+jdSchwWqlmI2tfkQ5M+49aswBuQ8+HGw/r7ZtI/PmdXNkJck1o14w1I5YUb9NLPk/P2Y8MVBMpQC2OyR
+ca9wG4HgxgYLm0jaFDpbfqG5DAv7BVhwW+9xJqpY87LXRlKVcbnc7a5GWaxdi1bGRB2/1e8mNa7VHZK9
+7jOTfgp9ujObOrkdNJMt0oT3ri3MuQdtCFQaCkigAwoY3GQV1jw8EOxud4B3Nq1Y6HGZv2ll1bxcwBqN
+YUs0zgfaPJgVqAW9cO2W+hoavrOgkTcsXWP8h0EdfNY1bHKUwC1smAHKBTCDDbd5GDVxwyrgAwkwmTXR
+b7jGAAvK5qg33GDc55mK5LVaFxqNwSp2dI6OvB/Mt2AsS1dcLiENYEobUOT0mJ9pa27Lv3jxzdosC5au
+9s54iKoy3JJL3XqSGVL2PscMmAEGtiwEkhz9erXouL6bkkv78sUcr0vJ7yWT6p+v5nhNVhVof3cgJ78m
+BdPW0zS52kgnPVqnDAwWTDOLbu6e1t9jEzk3MuqxHpZlznSuouT7LHN7TV/MczK5QvM109shcobd4Wtn
+CETxA7sboDabdqOYdkN4fbSgh68pGYySt+739KCnGnd20OMJGaDQwQAzznhDfk2xo4GN5tbZ9DqGKkQi
+pUk1OhVhBiRihln8aJEGg0xvQZfSaSZFZMwCk+BnCRtVigzSnKoWY/HGQJI8lh17cXSy454cmcQ1sGnt
+l2PioHownZ8Fe4QxgTLbHza009QoeeNgyYKJDcB7i5LKiv1Zbi8SKmxUZZGzBol2JeTksam5C8WPoSF9
+yfXnSpV2RMHTk+S6YFxEyRvGBShJIa+xtC+sBssHvVjMihdR8mHFi09GQbuPM80o+bV6PBXZiBA/oU4R
+pJjp7ftSdlLtrtHDj96we+oYZ6T1Tefen9YHnuq0vvKnAztKyF0BhtxnMGiNZii7ryh6iJ6JFYdt0Cm8
+tHAEgcL/wMW/nMPzvRdwAxd+Ophd7HajKDSmSmdmt4PwdHMmD8HJ73aVu78eBa+U0qLc7aDxdn0mXTKQ
+wtENT+N0S+l3AxpQP393Js3Qs9tV4XI1556NbdqzmrNSHFWM2tfURZdeUxe8GcWn3RB+ACVYzdIVeXyf
+gNDpgjtTELzPB5SiJ9Zvhy+fFgANFjM+qxq0Lhi56Nf+4Zx6UBh5fkFICRsCI0kFTDC55nIVQ+CBvHGq
+Co4+atoHM9IqYLDQaHI3/trlMKq0Ds4ULEXq9fF1hgItmviRIpjAW38I453uGy4EFKhTlLYd1shyPUc9
+ENisubyNnsXPI1iz+9voeQTGYkFNz74d2hvSWlJUnBKUEQ85/s86gmjQocJd7aGfDgBpdCWDTuXgDRcI
+Lmf2iSut7pbqCF6RCfNuV6EZEfBZ22cPV1Tsd08tvfrSe2aTkWrfrK3trI2z2FuiGz62b7amP7B3PjyM
+jggLc3MUbo4LpRH+Bq/n5Om3Fg349uuz6bKFRX2IzjXHT45sGf/p1NJYZs1gPkydcTaHv/9u7TRUgvlA
+fS5NpofTHa8neMTt9h5NBGb6fHKnBcDZ7MND33hXD//A/6IVdyuz210PgZLs2qDAJZSmRysKV+hSiyFU
+1N9GdRhAvNGIVFO7GWRdI/7GlviuqV7U6Mo0Q5RRUnHzYFxo95wfU/MWC98LodKm+Lg1KBZgmSuHjYyk
+2b2VpRkVw8B4VaD8/f4dhZAFSl+Fs5pJ483PHLUtpyq0Bv40eOWOA0pp3bKxoOHAZSrKzJ/ZSDSu3uXZ
+iU87vgD3l/amDKXB7FOOM44cY5ixfrc6FKGOAdFuP9b/IxY2HwP4QTOZ5l55xuB+QbY4DkXJ6kKozXHI
+qth06YOeq885iFGbbgztlU2rzeApDCFwt+Ruo4eLgmVkQhOBC3txA5dabeKMJPcL3qGAr+HFM/gGvr2C
+b+CiuL/YUexNML6qPnYY4uFWuG2a7PEBpuVhjsNT+AVfw/Nnz2oyN892u/8+PtJN8xQSc6coXe9zfJxA
+tjh/lKdWqdM7+AYqXPu2s/A55WiOgUvf4/Xvh4P+q88/3jm3cj5wicHpMu3VIxcLWlv5e3TZyeMVvb9E
+zkeX2MipfnC/oeBrem6eVAJj2RKHIoolxgFBnXk/PByDObyQB3BWALQ82BROSTz/lXOBEO7wXQNm3Jrr
+KlF0O1pVfFc6NNdbl6/NKyEwpdYcNQKXxiLLQC1gjrRvD1ZlbI4SUrVeU38GVi2RDs6AS1ASm5uwI81N
+yjSV/uH7sDzADayZXlXnV1VxojrYM24zVvqAsmc/YMmIYCunNlymWB0MesH2btInJmCd65LgVKzSsIFE
+64QAIHB2hg6eEzC8dmOPb5lHQoqx7reyk3IOg3qr/EI3JyplkjAowuDn3GI8+BusN9WhUKV1u2jY5wdQ
+Vex2I3d3Hd56PTvoo6R6qk+hxnaZMNpnqyeBrnA7Cll7PpfAtiQy55LpbS0RDxH7VhedtDvcqcJJtFx2
+O0bKAfRQ8u2nE+q9exGuFf7oPc/QXhcck1PRS0/+UW5jnHvyvN+unx51BQNT8c7Yz+TKFWTW3H7W0Xh/
+PPHJDLZkfRUlYWmACfFobA6HM0t8/HCm557aiTcoW1S6ajXmoAddc31RrdvVVduOA835wbcWDX67MYnz
+OTm3g75pD4HS6u2QYwpZU9cx7MfVXqGrEWOXmCEce1eLsEdXdQSv0OTQXXPKUKbYl/VA1W2j1rw8wr6w
+z5cr9pDBj42cGVKG1sNzKdP6rpErEz29vYVSZrjgkqLFJpGQ5LXri9ctPg7QNcCbfb1nOzYbVqnaB3Zj
+3VaLM2bSsckdx01TA+siS0sJw/Pe2t17FGD27wXTKNunG1ECs6l/d8TanHWn9+RJ+3umI6cpfbnL0CkL
+yzJvxyd/RZGTqsEpBxkSNyHRZOvu8YTvcAcU0ed+XFDTeWt+owOTBOin53qzQUO3Rkx1y/na5x6URay5
+MfQ7r76uOvw24ZyTieQ1IcaAbuSG+b4lfAi3l/hULicW14VgNiRhlDytVcZETF9iRkOZmwOZ0MdyqFtA
+DjB/2YZz/qYveG9kwty8w009KYkbcAbbY4cHOXQY+lPG7cig2TR/mYyZwOH86BvA/pC4SV7i5ifnWzTb
+9EIDzEjYTCNrz7atr+9w47K9+rOPoIc1frpTTFFZwHUiqSCePoQSNz93cR5BesCxc+zDPLvuY1wPLHJb
+zl9CzHBJads/X1318q7ZoXgeQeTv2WNK/egU/vy8FTjRWbZXB96zjd99a/YGj2Pbhuc/e3V/Dz7arGkI
+ZcwWvvoKTrG933NugrwyhQakspAqeUd06MtJsMrfM2BrDDy7K9TU9vBQEwhfnPqLxXEvpQ/sjvw7r8oy
+7p7D+jtXkKJH0LVUrIIVYuEAAO9ZauNTVuUEn7VQyvb55P5PWXvu/aoV7dO//nwsG+lgai5WwOS/zTry
+VVY3uWh+ud3qrh9nU/9t9mzq/mfA/w8AWka9RUNAAAA=
 `,
 	},
 
 	"/html/js/boltguiapp.js": {
 		local:   "html/js/boltguiapp.js",
-		size:    11322,
-		modtime: 1445514930,
+		size:    37840,
+		modtime: 1792197227,
 		compressed: `
-H4sIAAAJbogA/+xa3W/jxhF/91+xYA+ghEp0AuRJtgwkgdsGbZOizeXleg80uZIIr0iBu7ItHPS/d/b7
-W5J91/QDMWCJ2h3Ozs78ZnZmyLpf70k9Vtuh3RM8Kb8bCPvj+x/KGfpQ7rvqYRgYZWO9g4Hy77jZj7Qb
-+j9hssNj+XF6c5W7f3qFUNUMPRsHQvAIE/vmETP6vRkCjqt93zDgN3lHm2GHZ+jdhrEdfAG3mkzRJ2CC
-0FM9ogd59186ytASsU1Hb67EpDNR1cCVUZj/8PEmmlTXajaa7uh927GuXwPBqiYUKxohUbXGbFJew6fa
-RTmt6L5pMKUTs4cR093QU6zFRkiPVKthvK+bjaV9qsneIUxKWu32dDP5ET/LNSeWGKG+3uIFElxmzjAG
-3XaYLmCL7jDdPyiefMZMHGfustNpUhjY873kqoRWVMepVDH/Fhd6bwgk5nccJo/4MEPBTrktuZQHULPd
-EFAu+IcVWtwW7RCDiRYWNa4CEQKw0YHgigzrSUFZPTJBXyhBYyKOImfyeOV/j5jtx15KK7cab1SZRips
-hnb1COT+bntN5e1YUi7Ut92hNKzkV/EfzuZTxvVNa8frthVGyGqLiwbebESTLuXPCy/8oQdd9g0GGumW
-FXhq76ERIYa3O1Iz/H4kC1RytUvSDduScuaR2pgAlH91V/iejSExeNBAnkAj/nJSGfnNeQbs94TcBJPH
-WTDQUbDmZfzYuMcRv6vcL+Mf8s9TaQXb2xNWsQ0o1Cwtthau7+LWmG3qi9GtkJ2rFFyqVUcYxN986Am2
-J+YrcEa0XEo18x/hfqcVwf2abdAd+ipm5gXltv2Wx+VJ0cJZgcdihgoBTfTcwf18obJAv7dLwXVRopqM
-uG4PCL9wJsU01LiU9ibQu/dThO5JKNsWs83QAvb+9tM/fi5DHOwFgK+pjHqHaL6tWb1A7yrw23ob8dY7
-X1jX4gH0D4DAH8GXJ9OQnQp9Zu/xvAqEkkL8iEwR3rQBxeGRxk6DUMmPX+A1//mwwyXstN7tSNfUHBnX
-L/Pn5+c5nFXbOagB983Q4rY8jfSpr/IYfuIQk5C+CdzCXPsBPoxbCX+4JHh1fYtfYC4WScz8tDJS/V9G
-PUH6JcOeSooChqfj3peIfL958X/eiz8Il/mIljGsAkc21yPeDk/4Ql/Wvsr9OOum/i2x+zsE/CSUPO/Q
-/OsQUd4qlGsOS+oZ+toPUi7PFA5PoVBhsMUkhcHTCHwl/nz0Bab1ibOo+lxM+b6fhARkAt+pjRlA8BTX
-t4+wjk1rT1VBOl3282T+lymEsqWQKIZEPZA+lySaI+klK1/+t+NEsn8TUB4SKPlvwEF0uGpPT9W72uEf
-4uT2tEOnmJ32a3NlteLoLnsg6gqAQ1RWbr7Keeo6n/NsVpBwXKbqyytXbJuoh02CKFiaUtLPrkzBbQ8g
-96SxkHbsodZ2/CxaPga3XT/vn7oUNrTJ5XVppolu3AI7aD9o9fLGTGwWxakgQF2cYqO6GC6XcIdBr0fd
-Uc78ur3eBm7D2WgXjK19UafINrkyvnFBFefVb71QmOblgHD64auPjldyx+I0BlFuWedOuH0zuZrqbV0O
-X+QxfCV2g2hi5HaQGIlu57LSv84BUMg3h37+HcruXx2vEv1LfUCmoa73/GWxIXkZT+Se5oidKfPPFfhq
-G6Kwzy5zrtD3S3wTNsMDNne42iIgOljzh6p/oJ7WjeWXPE0/5yQ95iJ2okPsxGeneZgQPdlKvAoBmjfX
-EpWlAm+ige6mSGeD7OXmS+VFl5ovkw/92pZ7Y96Tz3lek+8czxjsXjXjA3vNpMR+K1s0BYO0RZWGPHKH
-yUW+xHozFMJS6mIkhCWCfuLwa4Miaw3T+UrZwhyljjEua1Nd2KK6uD2VbE2da0vlWlJeO+pcKyrXhjqm
-XU+r6i1tp7iEyxdwp1pNp2q3M/g83VI62U7yS71Mofd5ZZ6j8/Cp4ZmWkUl/Uo9v1TOrU6fH/ybyw0dQ
-bwF++Ngpg3t5+RbU2/Tydc+OLn1udOKZ0b/5eVH4rMjp6/3m6q9x9Yyvpx7ynHF0YWLX0dmBv/+xpWuL
-DO8W8WpHmPAC+YJ/WC1wNgvxeYEcDRkojiQJEp+EFOlcK3mqv3SZYu6VuQ9nZEZN0CWDNCN4OBnqdmIl
-UdEg+3aO92pOIuwlXs3xYorKSWYyeqnURJJW+v0OE/jVuCCFcfHNx0WGK+4XYiuyXrUEnPclhIMUxezK
-84misLvFkBVcwiTysox/SWMqZsNjyoa+QiSYJsHywiAer4YTkwv4tR3ddpROSnlHqVmdsWvbjRj4PsGg
-BOIvHX527Rm8xCVXVzFcCgLHBbh1AxGruFc6F7LbwKLfXimWxiY6yBVLeaVsI6f1WQxIvm27J9SQmtJl
-oSjv/imI3JlmHChFD6zn//MXWqB+PW/A6x6XhWq8uvWmThOKu9tr4KIY6r/bzTcIoI75gowNfSGi9ZwN
-6zUfbMAL6h3FBdqMeLUsfvfpU6p8PB4LVI9dPccvuxo8v10W/EhWg8qdQPTs3Xdmhp8Ax+Pt9eabUFJX
-BUasrj3F1efg83jGhMQUQCOZzZ8AG4ExuKJHvMM1WxamzwXFYNwxLtSQQ1coZFjT3l47S6Ul4SYREdva
-R4uk7L8bu209HlwQKGl0yjgB0/PgItyYr8n5pJZj9QPBmr/4kdIPJxyT43xmA7uCj/z0n/HhDMUvPNBk
-aWAivTpI5dpHBlprG3Ucp3fE7249/yoCr4i063QI1OnODcraPH/AuImwHOEwgs4Ti7CryPOiR+KZklkL
-F+KmxasaEt/ijr/ameWe0TYMc3jE/pWKMNkxdWY3w3bXEffBEiZ4670reH2N3lOMIEnX1PZNw9U4bMVM
-ELxn9t5v+9Y+nsKIdP0jf5nVrEen6HnTNRsECYmko+pmdVfAulJCGEF121qeZ3AK/SsAAP///68v5zos
-AAA=
+H4sIAAAAAAAC/9x9f3Mbt5Lg//wUbV7ukTxTpLKVd3VHiXbF3vjF+xI7ZTvZrdLTHyAHFLEaDiYAKIlr
+87tfNX4NMIMhh7KSd7WpisUBGo0G0OhuNBoAKW62ORGTDc+2OR0OXvFc/e3Xt4MxXA22bLLgXEklSDkY
+w+ADXW6FZLz4keYlFYPr0UWvN52C4q+IpP/7O6DFkmdUAgGpBCtugEj49dObs/8Di52iElgBCw05BrWm
+sOJiA4LcQ0E2VCImUmRwS3cSFLmlCI5g3//ydtJbbYulYrzwlQ2lEiP43AMQVG1FAQvFyXBbULkkJR0a
+Un798PY135S8oIXSBUaji95eE12SG/qR/RcFJnUtxXazoAL4CgS/l7CiarmmGZRUwPSGqh8KJRiVIOjv
+WyrVpHdHRIVjDt+en5vOoIUSu1+IIBsJiy3LM1m1la+AwFQabDuPC354YFJhf1FbCxFUd0d+T3YSSJYJ
+KiXNYLFDZEzoXruluzFIbjtsTRQWg4IruCM5y2zPL0mBqKRieQ4LCjRjimZBhwYEDxfb5S1VvxC1Hpt0
+08OmrQgBc50AYCBn4PhH8X+TvAgQjHoA+4teD4CtYKiRTQS5/zu1OMFitIkwhxDmAksDzSWNgW8DyFsL
+1qijWcFvJN/SsAqd0FbJXQR950DDWnA4iXIVAege5oLqscYudsxrAPVPg/aeSMiooktFMyDSlrc1W2hX
+tfk0de975n/L7aaAY2YN/r4EzIr5LWRHVigOpABeYs50QdRyDVzAVCpyQ+ss8b4c8nIMLSxhyXDDTx8U
+LbLhYV4a2+7i5Qx4OY74qILGdppZ2iabRj2AyZIXSvA8p2I4eKULy9c+aTAG15jhN3LJSzqGbwTn6qP9
+vVaqHMM3G56R3I0icrkhQ/7EJI6CWjOpWVgPsKAke1/kO1izjEqgd1TswJJh5t893+YZbHjGVnqiQkYU
+QYk30TgqCiYe1xyUcOylqZrcUDUcoMh5W6z4YDSR2+WSSjn0DRJUlryQtOK+NGIH59MumuAoJJchrE5I
+AJZErUM4/LaMObJdFPTdhORUKAlzuLq+aGTa3za3kc3kDxnT4nAOK5JLetHz41PQB2VG+20GczivRqfk
+UoGkRSadqCISiJkHisNW5GYQPJNjgeFW5GMLX3Wn5W09HEOXCLChas2zGQx+ef/x02Ds07cin+E/VQoO
++wy+mWjEQ4u+yl5TklEhZ1DhBhgg99JCnX3alXQwgwEpy5wtCdI6fTi7v78/w7acbUVudFs28KX39hcO
+hfmueDbnJANBy5wsqRENpq9BCUrhnqm1TlS8hJze0dxmyzHcr9lyDVjeYTO6xyupG8IKuF/TAuhDSYoM
+1Up9MC0B82o+Bmwb87udxd1Y/hBHOQjPrCsufiDLdYVPi+JR1P8JdJNyK9fDd/TeUGZKjUPI0aiqbe9/
+70cTKgQX3em3lsAbwnKaVdAX9XG96LV08NBNQlclvKP32soYSr4Vy6DmO2KGcOd1Of53S3czMKCoWCtm
+1Y32WaYLqh7Wytrnms8o+7eouEuoQBasIKLCYD6rbC2OfK7+qjJzLmVVVH9VmZL9V1UvflRZ22LJN6Ux
+qT6GYPWMqojRxB7QfIZkFiuW0WJJA1pdUgWGhsEsNRMsDslzbMfNsC8VEUrD90cXLUConUL+68V/rRTT
+Qx1KhZBDLGMbftKCkBYqZpXCQUXswlAOmmJnA3geyeXnz6sWG4wz+7dKR4vfKf0JfkRM8y7Mtd8VgJU/
+M7i6rhLldmHga+lI2Ay2RUZXrKBZyDwko9nMaJg4mRU3jfQVyxUVscguBV2xhxkMAmUAsBJ8U09TvJ4i
+yL2toxrAmIpWRkEr9BkO/sS0YQT+42cu6HB00YYSs1vR4mAvt8IPtjF+4mp9/sT2E3z5Uk+lGfzlL/Cs
+SsQhGI0sP1oxVVVZW1fE0rGxvqiw3hhrcTgajaOCeubMIK4+BsnZhqmZX70FefuIvKuBGWFc++Ko4l/F
+B9dNfYIcPKq1ABvnVhIVNYaPrrDA9UUEr4fVKqZEX9UHwCAKlzvVf6ZPrwaC3OvJSTZ0slwT8b0ano8m
+iv9allS8JpIORy5b5mxJh9+OrmEOwYrH/RctkxoV6da0FAx7dxS1qMlMoTGcNBHsKnwwrlFi6JjZv1GV
+3YyJyFywAqY5zMH6ByDVEFfSGQ5GA5tio1rHjC566eorUdakwGQcIiEoXTNfnJT3sEdJiidRaP7j90UL
+rBUCzcH0dlGr6TOarFhB8nw3bBFSaa6xq4S4JQkZiCb17o2V5C01aFlqx7FmT9rMqofT+bavvM5pAPgO
+atDdRZCTLNNs9WhBjvl64fu2kIoUSwpzuxKe8JIWw7i/Fd2UOVH0V1zlDNAiMaBrtckHsVytluQzGPwc
+1vBaiTqwoJLnd3TWYGV6sHGRbVNs8/yilrkf1xKYfEfvu+GrM2xdgB0UZ1GXTgSV21xN1JoWR+RHaNJV
+U/PigOj38klz8sFVTdMUdP62l0Zau8957H+Dmc2+DfNu6a7eO6NJTosbtYYXcN6sOnIJZNn36BUY9jNS
+3FDRH0NfM7JZhGJFgz48j32FaF/4ulFZ9QdAcnRo7ICi13TSH130Uq2tq6FGfwbODfR/seLmMP0IRN+X
+cnjl3GODcqsGgTytbBLn8bp+DHHaMTHwbuLBOHLRHqjtBF13XGn5kg0lcVSId5DEKEfqUiwxO7qIMlZk
+9CGysVyDdM77lUXcsDz/e8hADfqUQrChlDpIwVPloN6DSBnMyzXKBtSNBgbFALwMPown/9m87suHmQO6
+q0EkjFKsSVC0WttqKuj9ByOAnjUlYwUTA9gNidPFTGC9oZBB96dh2rGta+w6Zuzo7ihY6tS40gdIMCCB
+5ZogQvPII0l4ZrE0aZhOYVv4OjJeDBQIJm9B0DPt80Rrj6nH1VqNcgdZeKVFBy5pvDys7XR83lupO4bm
+TLI+s5AhG3pz9OQ6wEyqr1MCiYY3vJE1PfB11STF1wmu0aaWaeicXsBfAXPDht9RaQgAxUGtqTZC1K6k
+GbDCc73zkYd4TJ4ZaGArYMpNjgl8XxjTBLkVETIJHHdkrBM+C/HwYkmN91BsnPvcMLWns64hE9OR31Fx
+L5iip2tP737pyl8XvW7TOaM5VTTaUr04ABDuybbBhNuhzXmOxLRP8qqad9TtN9dE/UXviL/D4iiaCOql
+e41CfohgXg1XZJLYKR+M/MBvTXWf042plrSMOq4crEUQLRSehRuLjX6rT8VTTbS62DYlX8DZt08rvFLD
++xU2ca+LXByDVERtZb0l2E6Tgyut787/r/afVgzzl784CeFRmSpwOTSB9x6QqZf90deqdlwDP0Kznyiy
+D4hobwKZX7IKqXDhDGZnt9yqMZCVosKICRS3NSnNcx1OZPYomdKxF9YCqkStr6+DoE2aT0aMqrWVsJXM
+rMtaXjY9Rrrw1od8VKvK0gROpIVukg7ACgzHxsmgIy4GRpgOxkllOzMV1sfd7u+FBvDB2e4E9lY1reEg
+LyU4DstvCModkd3t3mpD1SHJXZvLHXWdzvNjkposjzFtg/46oD63KqEXfW+1Bz6l2uj5p9zGHqikH4SX
+smUWS5rr+KbWBafztwW+1oZOSjrLIk+Wq6ajYFnycvfRFsEPZmWLQ+MDG6wxaBoNJnorRCRzItcgKSpn
+LKcnP2L/RMSN9rALSpQZUSASCkozHHZSZLEtaixQtaYbWFNhozEwcRLsLFdEB92JQKcbe76loSp2icOm
+aeeynI/vy5dwX69qcXLDytozCNW2bfPoTT4MeWwU89RuSHl0zybhEq3bMbU6FT9AaNUXE1nmDBs+GGlC
+XLxqHR2O4Ez/G6d7vT+DGL3X8+O6UUoLJWdacT9y5yvtprXF+2PQ7AYvof8zv6MZ9GEG/dc4gTLoo1M2
+iB7Tic+hH04m9OqmugrhGv5bP4pdd99iWZDaVWmqKbYybepgVHayn1tI82zmGxXbu3CJEWx1z0AH27KL
+77VuVmkSvs9z+0sCF7DMKREulNFuS5n+rMny7/M8kD6uOe07Zx1HrzF2J4p0E8KqI+Wo1PYVK4AXFJQg
+hSS6ZhS7IGjJhTLy3rokPR6NJGgd6rWUtrICTYM3RBkvmwIJEf1BM7L/r1r9Z3pu+emX2cRg/tXm16M5
+yKD+eEyx/wlaqDWSxJjXJ2gBgwceYyynPCQn285tTN7VcdxqlTX57KDP2c3wYcqtW5dHTet236tR/swt
+Vy2XaiatjWLIo3rV2sRbUainXMeWPakAP1V8d/ZDGvYyoQg0s59OEGvsJopfA8AG20/lGFixzLe4Vghx
+qTWX5tyHleA7Gki3uKavmriWmmY4U2PKmuSJCZ/S7gybgmFU4bfiqZFPMlHYN7pD7ILfkhUxUUMyNOLL
+cJKHNH35Ap4keOnm/wdcsA3Ay4NfbDxYr6NM6LXM9qeLLPvKeLJDUWS8/BMiyHj51dFjRjpc8fL6JPEQ
+BAMNO01bs17ruGvtXJ6RUdTu7UxPv6fRCFf/NAV3fbruaHf4Rj2Ji6wlNdBj+HbUitMabRnNnUv9iZag
+R1rffXvsZDd3rfHnLvzjz3HPkiwz5BgvB5VALHYwG+o+YByjtqGgUpntNBw/4EXg2/CovkorYa2RTooI
+aKgVI1ATAvHJZ5jpH0PKKfMMjw4siRpe+aOumubrUX1ynWbmxeMyh8Hgqwy96rSc2W8xuGueZxPmH8f3
+QxjjHzcxrCuMU01bhfVI18Uh4FTrm2JCulNIXeXEoi4kHiUC0ntkQfuc4li0BQd2EyABxkMC9GmFR6w+
+GxM+FdHc5SjCI6aqwWfMyXRXduD5x47T4VE6eYxalNw/g3ufgP/O3am6P4EPbWs77U0Y52pKNGOulWLX
+rfVgrjyxIl2mXlPRXs10qp3IQOSthBUXQFADp/coSJGB33Ow3RmvJKmgE9Ak2DgYIkGu+X0Bt5SWweUD
++kKHSpUbb/YBLd5pg7TQFVdQti/qYIayOZSCb0o11F5p2xpQHIapto9m/bHBP/lPzgrtn2+6mgzmL19c
+FfNGkbYlpuIwN6XqGwDRUm4MrMULZcwYW+EVu4aXmm78VVeT7X4jKwgqQdtVEuguOrrfofjo8ZsPh8Rz
+dYg1IYA6uirdT/qArl4dquvrrl/XEPS7Bx/GvDm2Zz7TlUhKxHL9Iz1wni5SPxr8bdE4szkOwxDUUVlR
+U2lKvuGiRnfyRGh8JNTbQtFp8RC3xZY+tm2xXF0fKv7OTuXO5afTaijcnSwZvy+QMSBnxS3wVSC2JBBl
+YyS4cFn3a55Th83dvWCiPxAU0dJNqXa1mwAqFjAI69zSckzRnQk2f3s1Nw/KE0TnvdYGw8TbzKkZeBF3
+0WBqSHuJPpjadQKtnV/14rx9AjSY/+q6xvCps+bV2i85sMfNslDIh4g9R76Vmu9epnPfaT+TF68wg6tW
+uMAbdnzNdni95mSm0QXRpm7vhOVYK6m1xVgo0mODdN8LevpA/7VdM+DHr65+O1bjjyUeDx+MQgedbmvt
+gaDtLeeJjp0kMrjMCaLWao6dH2rphqNL3fA0e6LaxNl2r9BbCznW2scXTnjDo/W+ipohfYzreh0WwCcu
+H7otHcLFVKoxrWupQ+uNFKL2ZdT+KS7jCH1iv+hIIOsXa8QQpY1yZ2c7ZDHrmJggUuwcibBhUuLWBzcX
+TN2T3aR3cKKnhXVglHeRtbHY+yq2qdmaKRvymP3YVZQesjE725fpW1cqGzvs37o347hKPNFLcUBEPWIy
+tU+lUybSvtfr4If4Q4TI18mClNvhqWTCv69ZTsEOuAnklWO/0YsrcSMnMuA2gJlmlW0rvBm75LnZfHYT
+XlJxRwUQqZHTzEYny7FdqBMJBDK2WpkLmqQKbm/yRjFuJUtFSQZ8BQuK8gRjyxQ14TOLXeWpb7JvHGPV
+YODASLak4ZUsrVMJZ+VHW659tVC7CEIXeMxNUZrCiaUruNHg0VLBRay/L/VFjToKaQxEuvv1FLm1MZ5j
+F1gaj9uk12ajhh0SBSXFAUkaPJpqR4KR9v8/9NtBuXdIpB6z5oPdzYYtH9822Nin31/XiNTzb7Nhht/0
+FHFBw9EYJsLOJvDva1qA5JtqKmvgzF0N6eYiSFYsKSbt4J4Kh3tsUoigsCHilmY+flifG8t3ljQjGprH
+yMJ+ChsRrwrDm8F8xK4HjrhKQ8/g2TP94wl56er64oidHwS+vfaNjkLfyq0OJ4Vyi2K2NSrO/DYQ0WrF
+wlqZbBhH6iI2CXs/iTeErcnxaHHRyRbpdGgofWCoNpitYnbYwTeHNRw6c2QGAc3Se7JLHjpq4b5h4njR
+vtWdWd+OeSrdnDG5JMJ0B2SCl4k5rf1KJM+tb2mjDXG+VZViDGmIMAYzzOBqTLEQfODOF8HL2gVrJnnC
+MtcSmMHnU0zjYMy/Rr+5mWr7Z0FX7qbbUktFIl3kmd4kcFYCL3onMWFTbJijTibV+9GS9kh1c0vyOlLN
+yzeBYSK2eXyDnL5asGmo1MyO1xpPN7sjWX+sNpO0kiz7sM1brKEm0oluSv3YV0mUoqKIb53TdzyGCfac
++mDQ6Z5JXPHUKdOGdAfy0kuH0IYid9T0rrlI2cxIPSpmsLT8RQtXRl5gtRZ8e6PPZ28SllSFNdmd1fLV
+jmyo70wDGoZU5OUybNTW7MCt9RXqsoV5/tD1LdugYziYMM7ZXbEPkpWzJaatCKuuNsnE7sO2MMv5sZ9u
+RdG8VdEE4ge3M9aZQmyLt4aQbYmNs0yx5pIWsGI5BcVhammt+ON+TVR4Ir/Jyx5vq2uEbcraUtpUcxHA
+aArmkPHldmN3b3/IKf58tXubDQemxBmCDfBms5zKq/PrcO2NYbDNaCSNm4sNzPVW7hsuNv9KFKmGFjMx
+fhEvohho9GNNTAuAHrrBGNs0qW1qRYBuRC2o+0wDm2G2oOYDN04TV1FX3iIEtawQyWybo9khdXOakcJ2
+sppeHZjtinC+auMbEz8YJq9mLstooZjanXgFc8yX0Y5ax2kcNas5fe25UWdP6k4cfe2kPnyFXjwE0VGp
+Q9If78U165pQJjA8FXQ++evY32Kv1qGIsDde1Kf9o4XBa0NFeHjTL6LM5fawElSu9WRwF1hbIqQ7Xjud
+mh1BInFNpXhSPLiaWuVD1CHpnvJsXyW1cH8I0DoJqtUZwsYrMz0QARZMCD2sah1lx0e9/ShFZOi0E9k9
+1Y401wd30+uKsj+Y7ZND0Jn79VZ7wPjZInGlMD4bElqUi50JM/rsN/nju4Xr/G1tY+XeHvFrEsWkYkvp
+Nrk9x6PCY0r6xSZ8WntXQ+BIhJzpENwcz1xKBSsmpAJeuFN/eOn7WDuPC2WeGMloqdaTNuNdyfZZ4Tqq
+0Xl+LriC6Ctzo+kuO9D1JqygbtegmjR91benYLIQpFiuv89zvvQbg5OckpVOSu+5ulolF6qqkoxhkY7J
+MFWeAdE/YpyPa0q14Wj3b7B/fERYfC/2deKg/kT35E84sjA33RoC6RGZIL+2Bs4aEMPCV+1vvFz7ng4L
+V0M78SNcFbLjDM/jrbjmboKhIX03cNMd3P3hAIM3WySFU9U3NcdU2CMoCoLzPNheL9B8izGi4vzpJFi9
+N04TXm+4CGdtGQUJtAUXtYy/Lnx9oEId7BSIy99jm+CGPtR0P8OF6oKrtYdas/gidx+Csa0UW2xHNBaU
+mor/+PGDCyayne3EqMnXYlxUm6jmfKorObc3zAYmyEdT7Pct1WcLpxYNntq3BSfunmUuqqAkL7W9CRJ4
+k+BHpkW1YDqOE/7t4/t3kLPCLnqJoNXWjvYJL/mGAisu/P6zhnZbxaAZDVaM5tXBZwK4SNsKChuWYXPN
+FII1KTK/9axbYeh1/GzuLhvDmlofNZQ5YQX8x88//ahU+cE9h5WwoT46PmhTFi6/yTzRGsl26+/1k4Jh
+sSUpljQ3NQ5H0UoqEbn1+8yN1e+hGaQZ02boryqTFT6HFeNe7Sb5v56fp8K/In4I5/KRULCoXCKyL9r1
+fVgLu06Mh6Qynkxf0yJ4L8dFLWjGq5wqhufQVjJubLMxwRTkRCoQpEgocUQxrHunH9ZiYj3Uz+bwL+fn
+ibhZ8w5JBnNswsTx2yf6oCZY3Vu7Yz34RzHAM4bfVvK2AW+OHmIbx4hy5OIWsGhT42IrEy8qmOSW8N41
+C8cJj2zqkdJl6lHEa6aMrO92qN/HMFXljh9KqH5bZkGBabT5mqlR2rCxTECLrMFJfkQzXtBY7zSkYa3m
+Nnu616tGixel4DeCyhbbEcC80jX5Rp/KHFq+qjj+IkLW/qRQHVESBGwr6wMXcO3ccm08gJ7dTz410jQ0
+AJTYtbxAkGK1OtPHsdKw1JvNQ2TsfcsO0NEjE/u2/jYK5Wk7vJ2yNop6DYaPTLQGO4b2YsjFD2sRNQ/v
+ex787YdPeAjb6vNUwO0oLCTRB5bYKQnUEJCFv3Kkbmw434RUfu9JR3V464Dkt+54u39DDtd32jACyWFl
+VluJ2K+IhtY43We+R+pq1WdMdAOGo0ZGTRI01XdaJjSMs7eFUaBhH5kbgwxKbf0YZ64MDpIybVv5ZTNb
+Kae9Nkwd3qbz9XaNLwg1cW2tk3ST5ZSIQ11/yGZprf73KKotAVCbCy2h21rfhGRh5OEYNvImTZ95w6++
+s7SRNzP8pzKCEM1M/9u+4xrN8MDiMJKFr6x9SjN87bV6ZPWgoAibcnj7LaFsXQHcOq/tcL+sJ8ygb40q
+S+SkX2thzANc0kZfH9gos/18eIssjvBn6sCLel2fLMTTBayKjvF7VDk3rw7WvHBaJ2ifRuu7nNGjnInL
+7xOPcka3xfsrtfV19disntcv7sk6f2G0TdegMDdFLuxTsKZ8L1BPhb3DNVgH6O3Qfn/cizZDq4TaE126
+/dR9HUHrL4SvYQ+upB/32i8SCPJ+CwvW388zF1gmqvMXY6bxut288JnZaCfcZUUv7tn39kxO9Nye7iqT
+bl7wC3jX9hS/TbGsHywdwlLr1PhaEnvhZQLG391nLJ+Yp8x8HNaKaZ6O6DPSOEVjDV/GJIZgDwemxMCh
+OjI1MiboUrE7OrTv5v3G6H04JWqva0fP7OJP/JBK6B3X/g+WSzXt1S6We26vP+/XPSf9ufnVNz017oUP
+W8xgcJmxO1jmRMp530K++IcGCnOWgksJC1Xg/2cPsg/FzRlbzfvP9Maf3/PT6cucLW/nfUNUFDLtlG7/
+xeU0Y3eJiqoq9B99AAzLt1Vo+Ecn2aiQkART3QQRDEf9F3hg9JSKjZo9W1NBE1irs4CI2+jzELv773L9
+HQieUyyoFC8SqIy87Wub70zxmxuExghgUkrah7Wgq3n/f3z+bMFZtt/3gQhGztwLrPM+mr020YpjOe/H
+RV74Tzy5st/HdAJcypIUrkNysqA56H/PMroi21wZZ6QfioV/uwLXRgF2m4KC6R18sU+s7/coIuUYGmDa
+j/7FHC3e7y+nSEW9D6fr7+pJIXv6rmJZo9GNVgYF72meNyEALu0bl3eM3tdmB7Ze0JISNe/7g+36ifv6
+Yfe+TQrg+naqVnPtchpUlaIEpbQjAX+fsUJ7/cxhLE2O3C42zOOMbzhKtQ7gkhXlVmnjbd5X9EH1oyos
+A2nkG57R3OOObvjqg97EW/M8o2LexyuabcYfUimuiWtVvhF8g2z1x1SoeK26T/rVfRjSh2W+leyOtnWv
+njYvovqXa7q8XfCH9uoEue+/gIU+anM5NSiS2I0csXjN4PfrcsxO2f4LwwiXU1OoA0InpeoIjQndWQ5j
+yjDFNRgu0hzYZqrio4SojO+1Q/62oePubrgDLb2c4rinp/vxDigF2xCx66L63ES0T/YhlWjraEvpEH1t
+c72g92eB+GmrvS4H3GmwJ5cC0dmr2hx5Zxbswd1Q/Sfg4tc6GttifdQQh7rNnH/uv/hB/01SR7DVRvF6
+fVIdm/7H4D8lL/4xGKGCwX2byyl58Wg8eYAIfmIFlY9Gt5R3Ftnrj7+lsSQV7EH2M/dgMl60cR/O9UX9
+QlcbuZvizPB2c3v7aJpJPn9uQ7vfV9fNJ3tKj3dMrrMQk1V9xXSorrZu6gu3K0gnNxPYSirkFA1Fdkdb
+yThNeUR3hPdfVO+CHFIhJ08/Xu4OqpCuSsQibMrKiCX0aQFnrx9SXG2sfLpOS2kZT4/XMo+SPIG9aa/y
+6+tQtDO51E6TLFoHmCdgk0apIoucOlT6o0W0KtE2SGrdpkA6MxzJc9cxpud0uO28v6hfK9yEH/VBMYWL
+G5OiD1oE949j17y4nKp1O/nHsv9Od0cgtCelFeZy2tJ5l0qEpr/W5YHZ78lvqzh7fMfH16XrHsoO1BOu
+2fvNpWc32yW49dTeW3qs3hefP3t/FC7jVPYCDnaGpfOzcR3FjqR9W0ceWKWyYsWti8s3NPR0eeZzhOoI
++0xHOPwv+Pb83K9VZ+f7/f8El636vmkGUcsiNVRbgcOvuc5OSQZcBNeo3ha4JS+olDTDB+v1k5M+/rAi
+yq6f/TJbL6THnogGmgA0zEv5LoJZkZ3O2U2u8u+/Op5q1Tj4MlNrrS2T9HKqhWIyJ922k5VW4PcotIVQ
+3JxlTJJFXYynPVGhgP+Jkww2XLQrlaQ3qTXNevRxQFke3k9FzeGEyqU6ncKvktqTNhraA4Nei2FOzS85
+rsp+X2TOQWmjR/Smpa9Pjuw2J1MWTsYRZzXUE0uEJ7Ty6qKrVjtYp1PD1iAobqZKIPoblnxbKGAF/J29
+GsPP7BVwAX9jr1DB6sNqZhdRrrnANeUBR6293GagqwkdtJFH1qcWoSu7gHk4Nd3Wqt+9w1CAbWF363wN
+g7+zV/jnZ/Pnb+zV4LqCZyZuBwCwN3OKtbyYw7fn//IdmtsMLg1Kaw/DGQT3IBQwNaA+Lun586pXfWuG
+DF5CMVH8DXug2fDbEcygwKibAeBOuMZ/xa5DT7cNo/9oTRegKOOIohKwefRBCxNkJIQye31mVPUwuFLa
+OY+4FNflFlwpvpl086OH9R8fKLvjZKkYA1FK+GO1d8Rcazh3+fqgjs6yCaiPsuHAUN6szXGAIPcTA/OJ
+l/AckU74aiWp+pGym7XCoatgbNoZ/DWKNYljYZDOSdjWIBCs2m614/L/BgD4+FnT0JMAAA==
 `,
 	},
 
@@ -1,28 +1,107 @@
 angular.module('BoltGUI', ['ui.bootstrap', 'RecursionHelper']);
+
+// toBase64 encodes a string as UTF-8 bytes in base64, the form raw names
+// and keys take in the API.
+function toBase64(str) {
+  return btoa(unescape(encodeURIComponent(str)));
+}
+
+// pageSize is the number of rows fetched per /getEntries request.
+var pageSize = 100;
+
+// entryParams builds the form of a /setEntry request. Existing entries are
+// always addressed by their raw key, so keys that are not valid UTF-8 can
+// still be edited.
+function entryParams(bucketPath, entry) {
+  var params = {
+    bucket: angular.toJson(bucketPath)
+  };
+
+  if (entry.rawKey) {
+    params.rawKey = entry.rawKey;
+  } else {
+    params.key = entry.key;
+  }
+
+  if (entry.raw) {
+    params.rawValue = entry.rawValue;
+  } else {
+    params.value = entry.value;
+    if (entry.format) {
+      // store the edit in the format the value was detected as
+      params.format = entry.format;
+    }
+  }
+  return params;
+}
+
+// entryOp turns the form of entryParams into an op of /batch or /stage.
+function entryOp(op, bucketPath, entry) {
+  return angular.extend(entryParams(bucketPath, entry), {
+    op: op,
+    bucket: bucketPath
+  });
+}
+
 angular.module('BoltGUI')
-  .controller('BucketsController', function($scope, $http, $modal) {
+  .controller('BucketsController', function($scope, $rootScope, $http, $modal) {
     var bucketsList = this;
 
+    // readOnly hides every control that would modify the database.
+    $rootScope.readOnly = true;
+    $http.get('/getInfo').success(function(response) {
+      $rootScope.readOnly = response.readOnly;
+      $rootScope.codec = response.codec;
+      $rootScope.path = response.path;
+    });
+
     bucketsList.alerts = [];
     bucketsList.buckets = [];
 
     bucketsList.isEditing = false;
 
-    $http.get('/getBuckets').success(function(response) {
-      response.forEach(function(value) {
-        bucketsList.buckets.push(NewBucket({
-          name: value,
-          entries: [],
-          subbuckets: []
-        }, bucketsList))
-        bucketsList.getEntries(value)
+    var nextBucketId = 0;
+
+    // post sends params as a form to url.
+    function post(url, params) {
+      return $http({
+        method: 'POST',
+        url: url,
+        data: $.param(params),
+        headers: {
+          'Content-Type': 'application/x-www-form-urlencoded'
+        }
       });
-    });
+    }
+
+    // reload replaces the bucket tree with the top level buckets, which load
+    // their entries again when expanded.
+    bucketsList.reload = function() {
+      $http.get('/getBuckets').success(function(response) {
+        bucketsList.buckets = [];
+        response.forEach(function(value) {
+          bucketsList.buckets.push(NewBucket(value, bucketsList));
+        });
+      }).error(function(response) {
+        bucketsList.requestFailed(response);
+      });
+    };
 
-    function NewEntry(key, value) {
+    bucketsList.reload();
+
+    function NewEntry(source) {
       var entry = {
-        key: key,
-        value: value,
+        key: source.key,
+        value: source.value,
+        rawKey: source.rawKey,
+        rawValue: source.rawValue,
+        binary: source.binary,
+        codec: source.codec,
+        lossy: source.lossy,
+        size: source.size,
+        uncompressedSize: source.uncompressedSize,
+        format: source.format,
+        confidence: source.confidence,
         edit: function() {
           console.log("start edit");
           console.log(this);
@@ -33,10 +112,70 @@ angular.module('BoltGUI')
 
     function NewBucket(bucket, parent) {
       var newBucket = {
+        id: 'bucket-' + nextBucketId++,
         parent: parent,
         name: bucket.name,
+        rawName: bucket.rawName,
         entries: [],
         subbuckets: [],
+        next: undefined,
+        loaded: false,
+        loading: false,
+        filter: {
+          prefix: '',
+          from: '',
+          to: '',
+          raw: false
+        },
+        load: function() {
+          if (!this.loaded) this.loadMore();
+        },
+        loadMore: function() {
+          var curBucket = this;
+          if (curBucket.loading || (curBucket.loaded && !curBucket.next)) return;
+
+          var params = {
+            bucket: angular.toJson(curBucket.getPath()),
+            start: curBucket.next,
+            limit: pageSize
+          };
+
+          ['prefix', 'from', 'to'].forEach(function(name) {
+            var value = curBucket.filter[name];
+            if (!value) return;
+
+            if (curBucket.filter.raw) {
+              params['raw' + name.charAt(0).toUpperCase() + name.slice(1)] = value;
+            } else {
+              params[name] = value;
+            }
+          });
+
+          curBucket.loading = true;
+          $http.get('/getEntries', {
+            params: params
+          }).success(function(response) {
+            response.entries.forEach(function(entry) {
+              curBucket.entries.push(NewEntry(entry));
+            });
+
+            response.subbuckets.forEach(function(bucket) {
+              curBucket.subbuckets.push(NewBucket(bucket, curBucket));
+            });
+
+            curBucket.next = response.next;
+            curBucket.loaded = true;
+          }).error(bucketsList.requestFailed).finally(function() {
+            curBucket.loading = false;
+          });
+        },
+        applyFilter: function() {
+          this.entries = [];
+          this.subbuckets = [];
+          this.next = undefined;
+          this.loaded = false;
+          this.loadMore();
+        },
         addEntry: function() {
           var curBucket = this;
           var modalInstance = $modal.open({
@@ -55,26 +194,20 @@ angular.module('BoltGUI')
           modalInstance.result.then(function(entry) {
             console.log(curBucket);
             if (curBucket.entries.filter(function(value) {
-                return value.key == entry.key
+                return entry.rawKey ? value.rawKey == entry.rawKey : value.key == entry.key
               }).length > 0) {
-              bucketsList.addAlert("danger", "Entry with key '" + entry.key + "' already exist.");
+              bucketsList.addAlert("danger", "Entry with key '" + (entry.rawKey || entry.key) + "' already exist.");
               return;
             }
 
-            $http({
-              method: 'POST',
-              url: '/setEntry',
-              data: $.param({
-                bucket: curBucket.getFullName(),
-                key: entry.key,
-                value: entry.value
-              }),
-              headers: {
-                'Content-Type': 'application/x-www-form-urlencoded'
-              }
-            })
+            if ($rootScope.staging) {
+              bucketsList.stageOps([entryOp('put', curBucket.getPath(), entry)]);
+              return;
+            }
 
-            curBucket.entries.push(entry);
+            post('/setEntry', entryParams(curBucket.getPath(), entry)).success(function(response) {
+              curBucket.entries.push(NewEntry(response));
+            }).error(bucketsList.requestFailed);
           });
         },
         editEntry: function(entry) {
@@ -96,21 +229,208 @@ angular.module('BoltGUI')
 
 
 
-          modalInstance.result.then(function(entry) {
-            $http({
-              method: 'POST',
-              url: '/setEntry',
-              data: $.param({
-                bucket: curBucket.getFullName(),
-                key: entry.key,
-                value: entry.value
-              }),
-              headers: {
-                'Content-Type': 'application/x-www-form-urlencoded'
-              }
-            })
+          modalInstance.result.then(function(edited) {
+            var changed = edited.raw ? edited.rawValue !== entry.rawValue : edited.value !== entry.value;
+            var renamed = edited.raw ? edited.newRawKey !== entry.rawKey : edited.newKey !== entry.key;
+            if ($rootScope.staging) {
+              curBucket.stageEdit(entry, edited, changed, renamed);
+              return;
+            }
+            if (renamed) {
+              curBucket.renameEntry(entry, edited, changed, false);
+              return;
+            }
+            if (!changed) {
+              // unchanged, don't risk re-encoding it
+              return;
+            }
+            if (!edited.raw) {
+              curBucket.entries[index] = NewEntry(angular.extend({}, entry, {
+                value: edited.value
+              }));
+            }
 
-            curBucket.entries[index] = entry;
+            post('/setEntry', entryParams(curBucket.getPath(), edited)).success(function(response) {
+              curBucket.entries[index] = NewEntry(response);
+            }).error(function(response) {
+              curBucket.entries[index] = entry;
+              bucketsList.requestFailed(response);
+            });
+          });
+        },
+
+        // renameEntry moves entry to the key typed in edited, with the
+        // edited value if it changed. An existing key is only replaced
+        // once confirmed.
+        renameEntry: function(entry, edited, changed, overwrite) {
+          var curBucket = this;
+          var params = entryParams(curBucket.getPath(), edited);
+          if (!changed) {
+            delete params.value;
+            delete params.rawValue;
+            delete params.format;
+          }
+          if (edited.raw) {
+            params.rawNewKey = edited.newRawKey;
+          } else {
+            params.newKey = edited.newKey;
+          }
+          params.overwrite = overwrite;
+
+          post('/renameEntry', params).success(function(response) {
+            curBucket.entries = curBucket.entries.filter(function(value) {
+              return value.rawKey != response.rawKey;
+            });
+            var index = curBucket.entries.indexOf(entry);
+            if (index > -1) {
+              curBucket.entries[index] = NewEntry(response);
+            } else {
+              curBucket.entries.push(NewEntry(response));
+            }
+          }).error(function(response, status) {
+            if (status == 409 && !overwrite && confirm(response.error + ". Overwrite it?")) {
+              curBucket.renameEntry(entry, edited, changed, true);
+              return;
+            }
+            bucketsList.requestFailed(response);
+          });
+        },
+
+        // stageEdit stages the edit of entry as a put, after deleting the
+        // old key when it was renamed.
+        stageEdit: function(entry, edited, changed, renamed) {
+          var path = this.getPath();
+          var ops = [];
+          var put = entryOp('put', path, edited);
+          if (renamed) {
+            ops.push({
+              op: 'delete',
+              bucket: path,
+              rawKey: entry.rawKey
+            });
+            delete put.key;
+            delete put.rawKey;
+            if (edited.raw) {
+              put.rawKey = edited.newRawKey;
+            } else {
+              put.key = edited.newKey;
+            }
+          }
+          if (!changed) {
+            if (!renamed) return;
+            // unchanged, don't risk re-encoding it
+            delete put.value;
+            delete put.format;
+            put.rawValue = entry.rawValue;
+          }
+          ops.push(put);
+          bucketsList.stageOps(ops);
+        },
+
+        selected: function() {
+          return this.entries.filter(function(entry) {
+            return entry.selected;
+          });
+        },
+
+        // copySelected copies the selected entries to the bucket at the
+        // slash separated path copyTarget, creating it as needed, and
+        // removes them here with move.
+        copySelected: function(move) {
+          var curBucket = this;
+          var selected = curBucket.selected();
+          if (!selected.length || !curBucket.copyTarget) return;
+
+          post('/copyEntries', {
+            bucket: angular.toJson(curBucket.getPath()),
+            keys: angular.toJson(selected.map(function(entry) {
+              return entry.rawKey;
+            })),
+            to: angular.toJson(curBucket.copyTarget.split('/').map(toBase64)),
+            move: move,
+            overwrite: curBucket.copyOverwrite,
+            parents: true
+          }).success(function(response) {
+            bucketsList.addAlert("success", (move ? "Moved " : "Copied ") + response.copied + " entries to " + curBucket.copyTarget + ".");
+            selected.forEach(function(entry) {
+              entry.selected = false;
+            });
+            if (move) {
+              curBucket.entries = curBucket.entries.filter(function(entry) {
+                return selected.indexOf(entry) < 0;
+              });
+            }
+          }).error(bucketsList.requestFailed);
+        },
+
+        // selectAll selects or clears every loaded entry.
+        selectAll: function(selected) {
+          this.entries.forEach(function(entry) {
+            entry.selected = selected;
+          });
+        },
+
+        // batch applies ops in one transaction and reports the result.
+        batch: function(ops) {
+          return post('/batch', {
+            ops: angular.toJson(ops)
+          }).success(function(response) {
+            bucketsList.addAlert("success", "Deleted " + response.deleted + " entries.");
+          }).error(bucketsList.requestFailed);
+        },
+
+        deleteSelected: function() {
+          var curBucket = this;
+          var selected = curBucket.selected();
+          if (!selected.length) return;
+
+          var ops = selected.map(function(entry) {
+            return {
+              op: 'delete',
+              bucket: curBucket.getPath(),
+              rawKey: entry.rawKey
+            };
+          });
+          if ($rootScope.staging) {
+            bucketsList.stageOps(ops).success(function() {
+              curBucket.selectAll(false);
+            });
+            return;
+          }
+
+          if (!confirm("Delete " + selected.length + " entries?")) return;
+          curBucket.batch(ops).success(function() {
+            curBucket.entries = curBucket.entries.filter(function(entry) {
+              return selected.indexOf(entry) < 0;
+            });
+          });
+        },
+
+        // deleteFiltered deletes every entry the filter matches, including
+        // those not loaded yet.
+        deleteFiltered: function() {
+          var curBucket = this;
+          var filter = curBucket.filter;
+          if (!filter.prefix && !filter.from && !filter.to) return;
+          if (!confirm("Delete every entry matching the filter?")) return;
+
+          var op = {
+            op: filter.from || filter.to ? 'deleteRange' : 'deletePrefix',
+            bucket: curBucket.getPath()
+          };
+          ['prefix', 'from', 'to'].forEach(function(name) {
+            var value = filter[name];
+            if (!value) return;
+
+            if (filter.raw) {
+              op['raw' + name.charAt(0).toUpperCase() + name.slice(1)] = value;
+            } else {
+              op[name] = value;
+            }
+          });
+
+          curBucket.batch([op]).success(function() {
+            curBucket.applyFilter();
           });
         },
 
@@ -118,93 +438,162 @@ angular.module('BoltGUI')
           var index = this.entries.indexOf(entry);
           var curBucket = this;
 
+          if ($rootScope.staging) {
+            bucketsList.stageOps([{
+              op: 'delete',
+              bucket: curBucket.getPath(),
+              rawKey: entry.rawKey
+            }]);
+            return;
+          }
+
           if (index > -1) {
             this.entries.splice(index, 1);
           }
 
-          $http({
-            method: 'POST',
-            url: '/delEntry',
-            data: $.param({
-              bucket: curBucket.getFullName(),
-              key: entry.key
-            }),
-            headers: {
-              'Content-Type': 'application/x-www-form-urlencoded'
+          post('/delEntry', {
+            bucket: angular.toJson(curBucket.getPath()),
+            rawKey: entry.rawKey
+          }).error(function(response) {
+            if (index > -1) {
+              curBucket.entries.splice(index, 0, entry);
             }
-          })
+            bucketsList.requestFailed(response);
+          });
         },
 
-        addBucket: function(name) {
-          this.subbuckets.push(NewBucket({
+        // addBucket creates a bucket named newBucketName nested in this one.
+        addBucket: function() {
+          var curBucket = this;
+          var name = curBucket.newBucketName;
+          if (!name) return;
+
+          if ($rootScope.staging) {
+            bucketsList.stageOps([{
+              op: 'createBucket',
+              bucket: curBucket.getPath().concat([toBase64(name)])
+            }]).success(function() {
+              curBucket.newBucketName = '';
+            });
+            return;
+          }
+
+          var bucket = NewBucket({
             name: name,
-            entries: [],
-            subbuckets: []
-          }, this));
-        },
-        removeBucket: function(bucket) {
-          $http({
-            method: 'POST',
-            url: '/delBucket',
-            data: $.param({
-              bucket: bucket.getFullName()
-            }),
-            headers: {
-              'Content-Type': 'application/x-www-form-urlencoded'
+            rawName: toBase64(name)
+          }, curBucket);
+          curBucket.subbuckets.push(bucket);
+          curBucket.newBucketName = '';
+
+          post('/setBucket', {
+            bucket: angular.toJson(bucket.getPath())
+          }).error(function(response) {
+            var index = curBucket.subbuckets.indexOf(bucket);
+            if (index > -1) {
+              curBucket.subbuckets.splice(index, 1);
             }
+            bucketsList.requestFailed(response);
           });
+        },
+        removeBucket: function(bucket) {
+          var curBucket = this;
+          if ($rootScope.staging) {
+            bucketsList.stageBucketDelete(bucket);
+            return;
+          }
 
-          var index = bucketsList.buckets.indexOf(bucket);
+          var index = curBucket.subbuckets.indexOf(bucket);
           if (index > -1) {
-            bucketsList.buckets.splice(index, 1);
+            curBucket.subbuckets.splice(index, 1);
           }
+
+          post('/delBucket', {
+            bucket: angular.toJson(bucket.getPath())
+          }).error(function(response) {
+            if (index > -1) {
+              curBucket.subbuckets.splice(index, 0, bucket);
+            }
+            bucketsList.requestFailed(response);
+          });
+        },
+        getPath: function() {
+          return this.parent.getPath().concat([this.rawName]);
+        },
+        getNames: function() {
+          return this.parent.getNames().concat([this.name]);
+        },
+        // move asks for a new slash separated path and moves the bucket
+        // there. Names typed as shown keep their raw bytes.
+        move: function() {
+          var path = this.getPath();
+          var names = this.getNames();
+          var typed = prompt("Move bucket to (slash separated path):", names.join('/'));
+          if (!typed || typed == names.join('/')) return;
+
+          var to = typed.split('/').map(function(name, i) {
+            return name == names[i] ? path[i] : toBase64(name);
+          });
+          post('/moveBucket', {
+            bucket: angular.toJson(path),
+            to: angular.toJson(to),
+            parents: true
+          }).success(function() {
+            bucketsList.reload();
+          }).error(bucketsList.requestFailed);
+        },
+        exportUrl: function(format) {
+          return exportUrl(this.getPath(), format);
+        },
+        searchHere: function() {
+          bucketsList.searchIn(this);
         },
-        getFullName: function() {
-          return this.parent.getFullName() + "--" + this.name;
+        stats: function() {
+          return bucketsList.statsFor(this.getPath());
         }
       }
 
-      bucket.entries.forEach(function(entry) {
-        newBucket.entries.push(NewEntry(entry.key, entry.value));
-      });
-
-      bucket.subbuckets.forEach(function(bucket) {
-        newBucket.subbuckets.push(NewBucket(bucket, newBucket));
-      });
-
       return newBucket;
     }
 
-    bucketsList.getFullName = function() {
-      return "list";
+    bucketsList.getPath = function() {
+      return [];
     }
 
-    bucketsList.getEntries = function(bucket) {
-      $http.get('/getEntries', {
-        params: {
-          buck: bucket
-        }
-      }).success(function(response) {
-        var buck = bucketsList.buckets.filter(function(value) {
-          return value.name == bucket;
-        })[0];
-
-        if (buck.entries.length > 0) buck.entries = [];
+    bucketsList.getNames = function() {
+      return [];
+    }
 
-        response.entries.forEach(function(entry) {
-          buck.entries.push(NewEntry(entry.key, entry.value));
-        });
+    // exportUrl is the download link of the buckets at path, or of the whole
+    // database when path is empty.
+    function exportUrl(path, format) {
+      var params = {
+        format: format
+      };
+      if (path.length) params.bucket = angular.toJson(path);
+      return '/export?' + $.param(params);
+    }
 
-        if (buck.subbuckets.length > 0) buck.subbuckets = [];
+    bucketsList.exportUrl = function(format) {
+      return exportUrl([], format);
+    };
 
-        response.subbuckets.forEach(function(bucket) {
-          buck.subbuckets.push(NewBucket(bucket, buck));
+    bucketsList.addBucket = function() {
+      if ($rootScope.staging) {
+        var names = bucketsList.newBucketIsPath ? bucketsList.newBucketName.split('/') : [bucketsList.newBucketName];
+        bucketsList.stageOps([{
+          op: 'createBucket',
+          bucket: names.map(toBase64)
+        }]).success(function() {
+          bucketsList.newBucketName = '';
         });
-      });
-    }
+        return;
+      }
 
+      if (bucketsList.newBucketIsPath) {
+        bucketsList.addBucketPath();
+        return;
+      }
 
-    bucketsList.addBucket = function() {
       if (bucketsList.buckets.filter(function(value) {
           return value.name == bucketsList.newBucketName
         }).length > 0) {
@@ -212,133 +601,377 @@ angular.module('BoltGUI')
         return;
       }
 
-      $http({
-        method: 'POST',
-        url: '/setBucket',
-        data: $.param({
-          bucket: bucketsList.newBucketName
-        }),
-        headers: {
-          'Content-Type': 'application/x-www-form-urlencoded'
+      var bucket = NewBucket({
+        name: bucketsList.newBucketName,
+        rawName: toBase64(bucketsList.newBucketName)
+      }, bucketsList);
+
+      bucketsList.buckets.push(bucket);
+      bucketsList.newBucketName = '';
+
+      post('/setBucket', {
+        bucket: angular.toJson(bucket.getPath())
+      }).error(function(response) {
+        var index = bucketsList.buckets.indexOf(bucket);
+        if (index > -1) {
+          bucketsList.buckets.splice(index, 1);
         }
+        bucketsList.requestFailed(response);
       });
+    };
 
-      bucketsList.buckets.push({
-        name: bucketsList.newBucketName,
-        entries: []
-      });
-      bucketsList.newBucketName = '';
+    // addBucketPath creates the bucket at the slash separated path typed as
+    // newBucketName, with any buckets missing on the way.
+    bucketsList.addBucketPath = function() {
+      var path = bucketsList.newBucketName.split('/').map(toBase64);
+
+      post('/setBucket', {
+        bucket: angular.toJson(path),
+        parents: true
+      }).success(function() {
+        bucketsList.newBucketName = '';
+        bucketsList.reload();
+      }).error(bucketsList.requestFailed);
     };
 
     bucketsList.removeBucket = function(bucket) {
-      $http({
-        method: 'POST',
-        url: '/delBucket',
-        data: $.param({
-          bucket: bucket.getFullName()
-        }),
-        headers: {
-          'Content-Type': 'application/x-www-form-urlencoded'
-        }
-      });
+      if ($rootScope.staging) {
+        bucketsList.stageBucketDelete(bucket);
+        return;
+      }
 
       var index = bucketsList.buckets.indexOf(bucket);
       if (index > -1) {
         bucketsList.buckets.splice(index, 1);
       }
+
+      post('/delBucket', {
+        bucket: angular.toJson(bucket.getPath())
+      }).error(function(response) {
+        if (index > -1) {
+          bucketsList.buckets.splice(index, 0, bucket);
+        }
+        bucketsList.requestFailed(response);
+      });
     };
 
-    bucketsList.removeEntry = function(bucket, index) {
-      var key = bucket.entries[index].key;
+    // While staging, edits, deletes and created or deleted buckets are
+    // collected on the server as staged changes, shown as a diff against
+    // the database, instead of being written one by one.
+    $rootScope.staging = false;
+    bucketsList.stage = {
+      changes: []
+    };
 
-      bucket.entries.splice(index, 1);
+    bucketsList.loadStage = function() {
+      return $http.get('/getStage').success(function(response) {
+        bucketsList.stage.changes = response;
+      }).error(bucketsList.requestFailed);
+    };
 
-      $http({
-        method: 'POST',
-        url: '/delEntry',
-        data: $.param({
-          bucket: bucket.name,
-          key: key
-        }),
-        headers: {
-          'Content-Type': 'application/x-www-form-urlencoded'
-        }
-      })
+    // stageOps adds ops, as /batch takes them, to the staged changes.
+    bucketsList.stageOps = function(ops) {
+      return post('/stage', {
+        ops: angular.toJson(ops)
+      }).success(function(response) {
+        bucketsList.stage.changes = response;
+      }).error(bucketsList.requestFailed);
     };
 
-    bucketsList.editEntry = function(bucket, entry) {
+    bucketsList.stageBucketDelete = function(bucket) {
+      bucketsList.stageOps([{
+        op: 'deleteBucket',
+        bucket: bucket.getPath()
+      }]);
+    };
 
-      var modalInstance = $modal.open({
-        templateUrl: 'editmodal.html',
-        controller: 'ModalInstanceCtrl',
-        resolve: {
-          entry: function() {
-            return entry;
-          },
-          isNew: function() {
-            return false;
-          }
+    // commitStage writes the staged changes in one transaction. When some
+    // changed in the database since they were staged, they are marked and
+    // only committed once confirmed.
+    bucketsList.commitStage = function(force) {
+      post('/commitStage', {
+        force: !!force
+      }).success(function(response) {
+        bucketsList.stage.changes = [];
+        bucketsList.addAlert("success", "Committed " + response.put + " puts, " + response.deleted + " deletes, " +
+          response.createdBuckets + " created and " + response.deletedBuckets + " deleted buckets.");
+        bucketsList.reload();
+      }).error(function(response, status) {
+        if (status == 409 && !force) {
+          bucketsList.loadStage().success(function() {
+            if (confirm(response.error + ". Commit anyway?")) {
+              bucketsList.commitStage(true);
+            }
+          });
+          return;
         }
+        bucketsList.requestFailed(response);
       });
+    };
 
+    // discardStage drops the staged change, or all of them without one.
+    bucketsList.discardStage = function(change) {
+      post('/discardStage', change ? {
+        id: change.id
+      } : {}).success(function() {
+        bucketsList.loadStage();
+      }).error(bucketsList.requestFailed);
+    };
 
+    // changes staged before the page was loaded keep staging on
+    bucketsList.loadStage().success(function(response) {
+      if (response.length) $rootScope.staging = true;
+    });
 
-      modalInstance.result.then(function(entry) {
-        $http({
-          method: 'POST',
-          url: '/setEntry',
-          data: $.param({
-            bucket: bucket.name,
-            key: entry.key,
-            value: entry.value
-          }),
-          headers: {
-            'Content-Type': 'application/x-www-form-urlencoded'
-          }
-        })
-        bucket.entries[index] = entry;
+    bucketsList.config = {
+      rules: [],
+      codecs: []
+    };
+
+    $http.get('/getConfig').success(function(response) {
+      bucketsList.config = response;
+    });
+
+    bucketsList.addRule = function() {
+      bucketsList.config.rules.push({
+        pattern: '',
+        key: '',
+        value: ''
       });
     };
 
-    bucketsList.addEntry = function(bucket) {
-      var modalInstance = $modal.open({
-        templateUrl: 'editmodal.html',
-        controller: 'ModalInstanceCtrl',
-        resolve: {
-          entry: function() {
-            return null;
-          },
-          isNew: function() {
-            return true;
-          }
+    bucketsList.removeRule = function(index) {
+      bucketsList.config.rules.splice(index, 1);
+    };
+
+    // saveConfig stores the codec rules and shows the buckets through them.
+    bucketsList.saveConfig = function() {
+      post('/setConfig', {
+        config: angular.toJson({
+          rules: bucketsList.config.rules
+        })
+      }).success(function(response) {
+        bucketsList.config = response;
+        bucketsList.reload();
+      }).error(bucketsList.requestFailed);
+    };
+
+    bucketsList.import = {
+      format: '',
+      conflict: 'fail',
+      dryRun: true,
+      running: false,
+      result: undefined
+    };
+
+    // runImport uploads the chosen file to /import and shows what it changed.
+    bucketsList.runImport = function() {
+      var imp = bucketsList.import;
+      var file = document.getElementById('import-file').files[0];
+      if (!file) return;
+
+      var form = new FormData();
+      form.append('file', file);
+      form.append('format', imp.format);
+      form.append('conflict', imp.conflict);
+      form.append('dryRun', imp.dryRun || $rootScope.readOnly);
+
+      imp.running = true;
+      imp.result = undefined;
+      $http.post('/import', form, {
+        transformRequest: angular.identity,
+        headers: {
+          'Content-Type': undefined
         }
+      }).success(function(response) {
+        imp.result = response;
+        if (!response.dryRun) bucketsList.reload();
+      }).error(bucketsList.requestFailed).finally(function() {
+        imp.running = false;
       });
+    };
 
-      modalInstance.result.then(function(entry) {
-        if (bucket.entries.filter(function(value) {
-            return value.key == entry.key
-          }).length > 0) {
-          bucketsList.addAlert("danger", "Entry with key '" + entry.key + "' already exist.");
-          return;
-        }
+    bucketsList.compaction = {
+      fill: 0.5,
+      path: '',
+      replace: false,
+      running: false,
+      result: undefined
+    };
 
-        $http({
-          method: 'POST',
-          url: '/setEntry',
-          data: $.param({
-            bucket: bucket.name,
-            key: entry.key,
-            value: entry.value
-          }),
-          headers: {
-            'Content-Type': 'application/x-www-form-urlencoded'
-          }
-        })
+    // runCompact copies the database into a fresh file, which replaces it
+    // when asked to.
+    bucketsList.runCompact = function() {
+      var compaction = bucketsList.compaction;
 
-        bucket.entries.push(entry);
+      compaction.running = true;
+      compaction.result = undefined;
+      post('/compact', {
+        fill: compaction.fill,
+        path: compaction.path,
+        replace: compaction.replace
+      }).success(function(response) {
+        compaction.result = response;
+        if (response.replaced) bucketsList.reload();
+      }).error(bucketsList.requestFailed).finally(function() {
+        compaction.running = false;
       });
     };
 
+    bucketsList.stats = {
+      db: undefined,
+      rows: [],
+      byPath: {},
+      loading: false
+    };
+
+    // loadStats fetches the statistics of the database and its buckets. The
+    // buckets are listed largest first on every level, indented by depth.
+    bucketsList.loadStats = function() {
+      var stats = bucketsList.stats;
+
+      function add(buckets, path, depth) {
+        buckets.forEach(function(bucket) {
+          bucket.size = bucket.branchAlloc + bucket.leafAlloc;
+        });
+        buckets.sort(function(a, b) {
+          return b.size - a.size;
+        });
+
+        buckets.forEach(function(bucket) {
+          var bucketPath = path.concat([bucket.rawName]);
+          bucket.depthLevel = depth;
+          stats.rows.push(bucket);
+          stats.byPath[angular.toJson(bucketPath)] = bucket;
+          add(bucket.buckets, bucketPath, depth + 1);
+        });
+      }
+
+      stats.loading = true;
+      $http.get('/getStats').success(function(response) {
+        stats.db = response;
+        stats.rows = [];
+        stats.byPath = {};
+        add(response.buckets, [], 0);
+      }).error(bucketsList.requestFailed).finally(function() {
+        stats.loading = false;
+      });
+    };
+
+    bucketsList.statsFor = function(path) {
+      return bucketsList.stats.byPath[angular.toJson(path)];
+    };
+
+    bucketsList.search = {
+      q: '',
+      regex: false,
+      in: 'both',
+      hits: [],
+      bucket: null,
+      running: false
+    };
+
+    // searchXHR is the request of the search under way.
+    var searchXHR = null;
+
+    // runSearch queries /search over search.bucket, or the whole database
+    // without one. Hits arrive as JSON lines and are shown as they come in;
+    // a line with an error field reports a failure midway. $http hands
+    // over whole responses only, hence the plain XMLHttpRequest.
+    bucketsList.runSearch = function() {
+      var search = bucketsList.search;
+      if (!search.q) return;
+      bucketsList.cancelSearch();
+
+      var params = {
+        q: search.q,
+        regex: search.regex,
+        in: search.in,
+        limit: 500
+      };
+      if (search.bucket) {
+        params.bucket = angular.toJson(search.bucket.getPath());
+      }
+
+      var xhr = new XMLHttpRequest();
+      var seen = 0;
+
+      // addHits shows the lines completed since it last ran
+      function addHits() {
+        if (xhr.status != 200) return;
+
+        var end = xhr.responseText.lastIndexOf('\n') + 1;
+        xhr.responseText.slice(seen, end).split('\n').forEach(function(line) {
+          if (!line) return;
+
+          var hit = angular.fromJson(line);
+          if (hit.error) {
+            bucketsList.addAlert("danger", hit.error);
+            return;
+          }
+          search.hits.push(hit);
+        });
+        seen = end;
+      }
+
+      function done() {
+        searchXHR = null;
+        search.running = false;
+      }
+
+      xhr.onprogress = function() {
+        $scope.$apply(addHits);
+      };
+      xhr.onload = function() {
+        $scope.$apply(function() {
+          done();
+          if (xhr.status == 200) {
+            addHits();
+            return;
+          }
+
+          var response;
+          try {
+            response = angular.fromJson(xhr.responseText);
+          } catch (e) {}
+          bucketsList.requestFailed(response);
+        });
+      };
+      xhr.onerror = function() {
+        $scope.$apply(function() {
+          done();
+          bucketsList.requestFailed();
+        });
+      };
+
+      search.hits = [];
+      search.running = true;
+      searchXHR = xhr;
+      xhr.open('GET', '/search?' + $.param(params));
+      xhr.send();
+    };
+
+    // cancelSearch aborts the search under way, which stops the server
+    // walking the database. The hits so far stay.
+    bucketsList.cancelSearch = function() {
+      if (!searchXHR) return;
+      searchXHR.abort();
+      searchXHR = null;
+      bucketsList.search.running = false;
+    };
+
+    // searchIn limits the searches to bucket and what is nested in it, or
+    // lifts the limit without one.
+    bucketsList.searchIn = function(bucket) {
+      bucketsList.search.bucket = bucket;
+    };
+
+    bucketsList.clearSearch = function() {
+      bucketsList.cancelSearch();
+      bucketsList.search.q = '';
+      bucketsList.search.hits = [];
+    };
+
     bucketsList.addAlert = function(type, msg) {
       bucketsList.alerts.push({
         msg: msg,
@@ -346,6 +979,11 @@ angular.module('BoltGUI')
       });
     };
 
+    // requestFailed shows the error of a failed API request.
+    bucketsList.requestFailed = function(response) {
+      bucketsList.addAlert("danger", response && response.error ? response.error : "Request failed.");
+    };
+
     bucketsList.closeAlert = function(index) {
       bucketsList.alerts.splice(index, 1);
     };
@@ -366,15 +1004,27 @@ angular.module('BoltGUI').controller('ModalInstanceCtrl', function($scope, $moda
   if (isNew)
     $scope.newEntry = {
       key: "",
-      value: ""
+      value: "",
+      raw: false
     };
   else
     $scope.newEntry = {
       key: entry.key,
-      value: entry.value
+      value: entry.value,
+      rawKey: entry.rawKey,
+      rawValue: entry.rawValue,
+      newKey: entry.key,
+      newRawKey: entry.rawKey,
+      format: entry.format,
+      codec: entry.codec,
+      lossy: entry.lossy,
+      raw: entry.binary
     };
 
   $scope.ok = function() {
+    if (isNew && !$scope.newEntry.raw) {
+      delete $scope.newEntry.rawKey;
+    }
     $modalInstance.close($scope.newEntry);
   };
 
@@ -391,25 +1041,65 @@ angular.module('BoltGUI').directive('bucketView', function(RecursionHelper) {
       bucket: "=bucket"
     },
     template: '<div class="bucket">\
-    <div class="cross btn btn-xs" ng-click="parent.removeBucket(bucket)"></div>\
-            <h4 role="button" data-toggle="collapse" href="#{{bucket.getFullName()}}" aria-expanded="true" aria-controls="{{bucket.getFullName()}}">{{bucket.name}}</h4>\
-            <div class="collapse" id="{{bucket.getFullName()}}">\
+    <div class="cross btn btn-xs" ng-if="!$root.readOnly" ng-click="parent.removeBucket(bucket)"></div>\
+    <div class="btn btn-xs btn-link move" ng-if="!$root.readOnly && !$root.staging" ng-click="bucket.move()">Move</div>\
+    <div class="btn btn-xs btn-link search-here" ng-click="bucket.searchHere()">Search</div>\
+            <h4 role="button" ng-click="bucket.load()" data-toggle="collapse" href="#{{bucket.id}}" aria-expanded="true" aria-controls="{{bucket.id}}">{{bucket.name}}\
+              <span class="label label-default stats" ng-if="bucket.stats()">{{bucket.stats().keyN | number}} keys, {{bucket.stats().size | bytes}}</span>\
+            </h4>\
+            <div class="collapse" id="{{bucket.id}}">\
               <div class="well">\
                 <bucket-view class="bucket" ng-repeat="subbucket in bucket.subbuckets" bucket="subbucket" parent="bucket"></bucket-view>\
-                <button type="button" class="btn btn-primary" ng-click="bucket.addEntry()">New entry</button>\
+                <form class="form-inline filter" ng-submit="bucket.applyFilter()">\
+                  <input type="text" class="form-control" ng-model="bucket.filter.prefix" placeholder="Key prefix">\
+                  <input type="text" class="form-control" ng-model="bucket.filter.from" placeholder="From key">\
+                  <input type="text" class="form-control" ng-model="bucket.filter.to" placeholder="To key (exclusive)">\
+                  <label><input type="checkbox" ng-model="bucket.filter.raw"> base64</label>\
+                  <button type="submit" class="btn btn-default">Filter</button>\
+                  <button type="button" class="btn btn-danger" ng-if="!$root.readOnly && !$root.staging && (bucket.filter.prefix || bucket.filter.from || bucket.filter.to)" ng-click="bucket.deleteFiltered()">Delete matching</button>\
+                </form>\
+                <button type="button" class="btn btn-primary" ng-if="!$root.readOnly" ng-click="bucket.addEntry()">New entry</button>\
+                <form class="form-inline new-bucket" ng-if="!$root.readOnly" ng-submit="bucket.addBucket()">\
+                  <input type="text" class="form-control" ng-model="bucket.newBucketName" placeholder="Nested bucket name">\
+                  <button type="submit" class="btn btn-default">Create bucket</button>\
+                </form>\
+                <span class="export">Export\
+                  <a ng-href="{{bucket.exportUrl(\'json\')}}">JSON</a>\
+                  <a ng-href="{{bucket.exportUrl(\'jsonl\')}}">JSON Lines</a>\
+                  <a ng-href="{{bucket.exportUrl(\'csv\')}}">CSV</a>\
+                </span>\
+                <form class="form-inline selection" ng-if="!$root.readOnly && bucket.selected().length" ng-submit="bucket.copySelected(false)">\
+                  {{bucket.selected().length}} selected:\
+                  <span ng-if="!$root.staging">\
+                    <input type="text" class="form-control" ng-model="bucket.copyTarget" placeholder="To bucket, e.g. users/archive">\
+                    <label><input type="checkbox" ng-model="bucket.copyOverwrite"> Overwrite</label>\
+                    <button type="submit" class="btn btn-default">Copy</button>\
+                    <button type="button" class="btn btn-default" ng-click="bucket.copySelected(true)">Move</button>\
+                  </span>\
+                  <button type="button" class="btn btn-danger" ng-click="bucket.deleteSelected()">Delete</button>\
+                </form>\
+                <div class="entries" when-scrolled="bucket.loadMore()">\
                 <table class="table">\
                   <tr>\
+                    <th ng-if="!$root.readOnly"><input type="checkbox" ng-model="bucket.allSelected" ng-change="bucket.selectAll(bucket.allSelected)" title="Select all loaded entries"></th>\
                     <th></th>\
                     <th>Key</th>\
                     <th>Value</th>\
                   </tr>\
                   <tr ng-repeat="entry in bucket.entries">\
-                    <td class="cross" role="button" ng-click="bucket.removeEntry(entry)"></td>\
+                    <td ng-if="!$root.readOnly"><input type="checkbox" ng-model="entry.selected"></td>\
+                    <td class="cross" role="button" ng-if="!$root.readOnly" ng-click="bucket.removeEntry(entry)"></td>\
                     <td>{{entry.key}}</td> \
-                    <td>{{entry.value}}</td>\
-                    <td ng-click="bucket.editEntry(entry)" class="btn btn-default">Edit</td>\
+                    <td ng-class="{binary: entry.binary}">\
+                      <span class="label label-info format" ng-if="entry.format" title="{{entry.confidence * 100 | number:0}}% confident">{{entry.format}}</span>\
+                      {{entry.value}}\
+                      <div class="size" ng-if="entry.uncompressedSize !== undefined">{{entry.size | number}} bytes, {{entry.uncompressedSize | number}} uncompressed</div>\
+                    </td>\
+                    <td ng-if="!$root.readOnly" ng-click="bucket.editEntry(entry)" class="btn btn-default">Edit</td>\
                   </tr>\
                 </table>\
+                </div>\
+                <button type="button" class="btn btn-default" ng-if="bucket.next" ng-disabled="bucket.loading" ng-click="bucket.loadMore()">Load more</button>\
               </div>\
             </div>\
             </div>',
@@ -420,4 +1110,32 @@ angular.module('BoltGUI').directive('bucketView', function(RecursionHelper) {
     }
   };
 
-});
\ No newline at end of file
+});
+
+// bytes renders a byte count in KiB, MiB or GiB where that is shorter.
+angular.module('BoltGUI').filter('bytes', function() {
+  return function(n) {
+    if (n === undefined) return '';
+    var units = ['bytes', 'KiB', 'MiB', 'GiB'];
+    var i = 0;
+    while (n >= 1024 && i < units.length - 1) {
+      n /= 1024;
+      i++;
+    }
+    return (i ? n.toFixed(1) : n) + ' ' + units[i];
+  };
+});
+
+// whenScrolled evaluates its expression when the element is scrolled close
+// to its bottom.
+angular.module('BoltGUI').directive('whenScrolled', function() {
+  return function(scope, element, attrs) {
+    var raw = element[0];
+
+    element.bind('scroll', function() {
+      if (raw.scrollTop + raw.offsetHeight >= raw.scrollHeight - 50) {
+        scope.$apply(attrs.whenScrolled);
+      }
+    });
+  };
+});
@@ -0,0 +1,656 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/boltdb/bolt"
+)
+
+// Conflict policies, for keys an import finds already set to another value.
+const (
+	conflictSkip      = "skip"
+	conflictOverwrite = "overwrite"
+	conflictFail      = "fail"
+)
+
+// maxConflicts bounds the conflicting keys an ImportResult lists.
+const maxConflicts = 100
+
+var errKeyExists = errors.New("key exists")
+
+// ImportOptions control how records are written.
+type ImportOptions struct {
+	Conflict string
+	// Chunk is the number of records written per transaction, 0 writes all
+	// of them in one.
+	Chunk  int
+	DryRun bool
+}
+
+// ImportResult counts what an import changed, or would change in a dry run.
+type ImportResult struct {
+	Records     int      `json:"records"`
+	Created     int      `json:"created"`
+	Overwritten int      `json:"overwritten"`
+	Skipped     int      `json:"skipped"`
+	Unchanged   int      `json:"unchanged"`
+	Buckets     int      `json:"buckets"`
+	Conflicts   []string `json:"conflicts"`
+	DryRun      bool     `json:"dryRun"`
+}
+
+// importSink receives what an import file holds: buckets, which may be
+// empty, and records, whose buckets need not have been announced.
+type importSink interface {
+	bucket(path BucketPath) error
+	record(r Record) error
+}
+
+// importFormats are the formats import reads, by name.
+var importFormats = map[string]func(r io.Reader, sink importSink) error{
+	"json":  importJSON,
+	"jsonl": importJSONL,
+	"csv":   importCSV,
+}
+
+// importFormat returns the format of a file by its name, jsonl if the name
+// does not tell.
+func importFormat(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "json"
+	case ".csv":
+		return "csv"
+	}
+	return "jsonl"
+}
+
+// importJSONL reads records as exportJSONL writes them. Bucket takes
+// precedence over Path, which is parsed like -bucket.
+func importJSONL(r io.Reader, sink importSink) error {
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var rec Record
+		if err := dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("record %d: %v", line, err)
+		}
+
+		if len(rec.Bucket) == 0 {
+			var err error
+			if rec.Bucket, err = parseDisplayPath(rec.Path); err != nil {
+				return fmt.Errorf("record %d: %v", line, err)
+			}
+		}
+		if err := sink.record(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// importCSV reads records as exportCSV writes them. Columns are found by
+// the header; key, value and bucket or path are required. Like in JSON
+// Lines, bucket takes precedence over path.
+func importCSV(r io.Reader, sink importSink) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("csv header: %v", err)
+	}
+
+	cols := make(map[string]int)
+	for i, name := range header {
+		cols[name] = i
+	}
+	for _, name := range []string{"key", "value"} {
+		if _, ok := cols[name]; !ok {
+			return fmt.Errorf("csv header: no %s column", name)
+		}
+	}
+	_, hasBucket := cols["bucket"]
+	if _, hasPath := cols["path"]; !hasBucket && !hasPath {
+		return errors.New("csv header: no bucket or path column")
+	}
+	field := func(line []string, name string) string {
+		if i, ok := cols[name]; ok {
+			return line[i]
+		}
+		return ""
+	}
+	flag := func(line []string, name string) (bool, error) {
+		if s := field(line, name); s != "" {
+			return strconv.ParseBool(s)
+		}
+		return false, nil
+	}
+
+	for n := 2; ; n++ {
+		line, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rec := Record{
+			Key:    field(line, "key"),
+			Value:  field(line, "value"),
+			Format: field(line, "format"),
+		}
+		if b := field(line, "bucket"); b != "" {
+			rec.Bucket, err = parseBucketPath(b)
+		} else {
+			rec.Bucket, err = parseDisplayPath(field(line, "path"))
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+		if rec.BinaryKey, err = flag(line, "binary_key"); err != nil {
+			return fmt.Errorf("line %d: binary_key: %v", n, err)
+		}
+		if rec.BinaryValue, err = flag(line, "binary_value"); err != nil {
+			return fmt.Errorf("line %d: binary_value: %v", n, err)
+		}
+
+		if err := sink.record(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// importJSON reads nested JSON as exportJSON writes it, one record at a
+// time. The name of a bucket must come before its entries and buckets.
+func importJSON(r io.Reader, sink importSink) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := importJSONBucket(dec, nil, sink); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func importJSONBucket(dec *json.Decoder, parent BucketPath, sink importSink) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	var (
+		name   string
+		binary bool
+		path   BucketPath
+	)
+	// open announces the bucket once its name is known
+	open := func() error {
+		if path != nil {
+			return nil
+		}
+		if name == "" {
+			return errors.New("bucket without a name")
+		}
+
+		b := []byte(name)
+		if binary {
+			var err error
+			if b, err = base64.StdEncoding.DecodeString(name); err != nil {
+				return fmt.Errorf("bucket name %q: %v", name, err)
+			}
+		}
+		path = parent.child(b)
+		return sink.bucket(path)
+	}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t {
+		case "name":
+			err = dec.Decode(&name)
+		case "binaryName":
+			err = dec.Decode(&binary)
+		case "entries", "buckets":
+			if err := open(); err != nil {
+				return err
+			}
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			for dec.More() && err == nil {
+				if t == "buckets" {
+					err = importJSONBucket(dec, path, sink)
+					continue
+				}
+
+				var rec Record
+				if err = dec.Decode(&rec); err == nil {
+					rec.Bucket = path
+					err = sink.record(rec)
+				}
+			}
+			if err == nil {
+				err = expectDelim(dec, ']')
+			}
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := open(); err != nil {
+		return err
+	}
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the delimiter d from dec.
+func expectDelim(dec *json.Decoder, d json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != d {
+		return fmt.Errorf("expected %v, found %v", d, t)
+	}
+	return nil
+}
+
+// importer collects what it receives as an importSink and writes it to db
+// a chunk of records at a time, each chunk in a transaction of its own once
+// it has been read, so that no transaction waits on the file. A dry run
+// only reads db and keeps what it would have written in memory.
+type importer struct {
+	opts    ImportOptions
+	pending []importItem
+	n       int // records in pending
+	result  ImportResult
+
+	// what a dry run created and wrote so far, by dryKey
+	dryBuckets map[string]bool
+	dryValues  map[string][]byte
+}
+
+// importItem is a bucket to create, or a record to write when it has a
+// record number.
+type importItem struct {
+	record     int
+	path       BucketPath
+	key, value []byte
+}
+
+func (im *importer) bucket(path BucketPath) error {
+	im.pending = append(im.pending, importItem{path: path})
+	return nil
+}
+
+func (im *importer) record(r Record) error {
+	im.result.Records++
+	n := im.result.Records
+
+	key, value, err := recordBytes(r)
+	if err != nil {
+		return fmt.Errorf("record %d: %v", n, err)
+	}
+
+	im.pending = append(im.pending, importItem{n, r.Bucket, key, value})
+	im.n++
+	if im.opts.Chunk > 0 && im.n >= im.opts.Chunk {
+		return im.flush()
+	}
+	return nil
+}
+
+// flush writes the pending items in one transaction.
+func (im *importer) flush() error {
+	items := im.pending
+	im.pending, im.n = nil, 0
+	if len(items) == 0 {
+		return nil
+	}
+
+	write := func(tx *bolt.Tx) error {
+		for _, it := range items {
+			var err error
+			if im.opts.DryRun {
+				err = im.dryWrite(tx, it)
+			} else {
+				err = im.write(tx, it)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if im.opts.DryRun {
+		return db.View(write)
+	}
+	return db.Update(write)
+}
+
+func (im *importer) write(tx *bolt.Tx, it importItem) error {
+	buck, err := im.bucketAt(tx, it.path)
+	if err != nil || it.record == 0 {
+		return err
+	}
+
+	put, err := im.check(it, buck.Get(it.key))
+	if err != nil || !put {
+		return err
+	}
+	if err := buck.Put(it.key, it.value); err != nil {
+		return fmt.Errorf("record %d: bucket %s: %w", it.record, it.path, err)
+	}
+	return nil
+}
+
+// bucketAt returns the bucket at path, creating it and its parents as
+// needed.
+func (im *importer) bucketAt(tx *bolt.Tx, path BucketPath) (*bolt.Bucket, error) {
+	var buck *bolt.Bucket
+	for i, name := range path {
+		var next *bolt.Bucket
+		if i == 0 {
+			next = tx.Bucket(name)
+		} else {
+			next = buck.Bucket(name)
+		}
+
+		if next == nil {
+			var err error
+			if i == 0 {
+				next, err = tx.CreateBucket(name)
+			} else {
+				next, err = buck.CreateBucket(name)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("bucket %s: %w", path[:i+1], err)
+			}
+			im.result.Buckets++
+		}
+		buck = next
+	}
+	return buck, nil
+}
+
+// dryWrite counts what write would change, without writing anything.
+func (im *importer) dryWrite(tx *bolt.Tx, it importItem) error {
+	// buck is nil below the buckets the dry run created
+	var buck *bolt.Bucket
+	for i, name := range it.path {
+		path := it.path[:i+1]
+		var next *bolt.Bucket
+		if i == 0 {
+			next = tx.Bucket(name)
+		} else if buck != nil {
+			next = buck.Bucket(name)
+		}
+
+		if next == nil && !im.dryBuckets[dryKey(path)] {
+			if buck != nil && buck.Get(name) != nil || im.dryValues[dryKey(path)] != nil {
+				return fmt.Errorf("bucket %s: %w", path, bolt.ErrIncompatibleValue)
+			}
+			im.dryBuckets[dryKey(path)] = true
+			im.result.Buckets++
+		}
+		buck = next
+	}
+	if it.record == 0 {
+		return nil
+	}
+
+	k := dryKey(it.path.child(it.key))
+	switch {
+	case len(it.key) == 0:
+		return fmt.Errorf("record %d: bucket %s: %w", it.record, it.path, bolt.ErrKeyRequired)
+	case buck != nil && buck.Bucket(it.key) != nil || im.dryBuckets[k]:
+		return fmt.Errorf("record %d: bucket %s: %w", it.record, it.path, bolt.ErrIncompatibleValue)
+	}
+
+	old, ok := im.dryValues[k]
+	if !ok && buck != nil {
+		old = buck.Get(it.key)
+	}
+	put, err := im.check(it, old)
+	if put {
+		im.dryValues[k] = it.value
+	}
+	return err
+}
+
+// dryKey identifies a bucket, or a key by the path of its bucket followed
+// by the key, in the maps of a dry run.
+func dryKey(path BucketPath) string {
+	var b []byte
+	for _, name := range path {
+		b = strconv.AppendInt(b, int64(len(name)), 10)
+		b = append(append(b, ':'), name...)
+	}
+	return string(b)
+}
+
+// check counts the record it by the value old of its key, nil for none, and
+// reports whether it is to be written.
+func (im *importer) check(it importItem, old []byte) (bool, error) {
+	switch {
+	case old == nil:
+		im.result.Created++
+		return true, nil
+	case bytes.Equal(old, it.value):
+		im.result.Unchanged++
+		return false, nil
+	}
+
+	if len(im.result.Conflicts) < maxConflicts {
+		im.result.Conflicts = append(im.result.Conflicts, it.path.String()+": "+printable(it.key))
+	}
+	switch im.opts.Conflict {
+	case conflictSkip:
+		im.result.Skipped++
+		return false, nil
+	case conflictOverwrite:
+		im.result.Overwritten++
+		return true, nil
+	}
+	return false, fmt.Errorf("record %d: bucket %s: key %s: %w", it.record, it.path, printable(it.key), errKeyExists)
+}
+
+// recordBytes converts the key and value of r to bytes, with the codecs of
+// its bucket unless they are flagged as base64.
+func recordBytes(r Record) ([]byte, []byte, error) {
+	var key, value []byte
+	var err error
+
+	keyCodec, _, _ := codecsFor(r.Bucket)
+	switch {
+	case r.BinaryKey:
+		key, err = base64.StdEncoding.DecodeString(r.Key)
+	case keyCodec != nil:
+		key, err = keyCodec.Encode(r.Key)
+	default:
+		key = []byte(r.Key)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("key %q: %v", r.Key, err)
+	}
+
+	if r.BinaryValue {
+		value, err = base64.StdEncoding.DecodeString(r.Value)
+	} else {
+		value, err = encodeEntry(r.Bucket, r.Value, r.Format)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("key %q: value: %v", r.Key, err)
+	}
+	return key, value, nil
+}
+
+// importRecords writes what read finds in an import file to db. Chunks
+// committed before a failure stay written.
+func importRecords(read func(io.Reader, importSink) error, r io.Reader, opts ImportOptions) (ImportResult, error) {
+	switch opts.Conflict {
+	case conflictSkip, conflictOverwrite, conflictFail:
+	case "":
+		opts.Conflict = conflictFail
+	default:
+		return ImportResult{}, fmt.Errorf("conflict policy must be %s, %s or %s", conflictSkip, conflictOverwrite, conflictFail)
+	}
+
+	im := &importer{
+		opts:       opts,
+		dryBuckets: make(map[string]bool),
+		dryValues:  make(map[string][]byte),
+	}
+	im.result.Conflicts = []string{}
+	im.result.DryRun = opts.DryRun
+
+	if err := read(r, im); err != nil {
+		return im.result, err
+	}
+	return im.result, im.flush()
+}
+
+func importHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("import takes a POST"))
+		return
+	}
+
+	// a multipart upload from the UI, or the file as the request body with
+	// the options in the query
+	var (
+		body  = io.Reader(r.Body)
+		name  string
+		value = r.URL.Query().Get
+	)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		defer f.Close()
+		body, name, value = f, h.Filename, r.FormValue
+	}
+
+	format := value("format")
+	if format == "" {
+		format = importFormat(name)
+	}
+	read, ok := importFormats[format]
+	if !ok {
+		writeError(w, http.StatusBadRequest, errors.New("format must be json, jsonl or csv"))
+		return
+	}
+
+	opts := ImportOptions{
+		Conflict: value("conflict"),
+		DryRun:   value("dryRun") == "true",
+	}
+	// a dry run only reads, so it is open to read-only databases too
+	if *readonly && !opts.DryRun {
+		writeError(w, http.StatusForbidden, bolt.ErrDatabaseReadOnly)
+		return
+	}
+	if s := value("chunk"); s != "" {
+		var err error
+		if opts.Chunk, err = strconv.Atoi(s); err != nil || opts.Chunk < 0 {
+			writeError(w, http.StatusBadRequest, errors.New("chunk must be a number of records"))
+			return
+		}
+	}
+
+	result, err := importRecords(read, body, opts)
+	if err != nil {
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			// most likely a malformed file
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	writeJSON(w, result)
+}
+
+// importMain runs the import subcommand:
+//
+//	BoltGUI import -path my.db [-format jsonl] [-conflict fail] [-chunk 0] [-dry-run] [file]
+//
+// It reads stdin when no file is given.
+func importMain(args []string) {
+	fs := subcommand("import")
+	format := fs.String("format", "", "Import format: json, jsonl or csv, by the file extension by default.")
+	conflict := fs.String("conflict", conflictFail, "What to do with keys set to another value: skip, overwrite or fail.")
+	chunk := fs.Int("chunk", 0, "Records written per transaction, 0 writes all in one.")
+	dryRun := fs.Bool("dry-run", false, "Report what would change without changing it.")
+	fs.Parse(args)
+	setup(fs)
+
+	in, name := io.Reader(os.Stdin), ""
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in, name = f, f.Name()
+	}
+
+	if *format == "" {
+		*format = importFormat(name)
+	}
+	read, ok := importFormats[*format]
+	if !ok {
+		fmt.Println("-format must be json, jsonl or csv")
+		os.Exit(2)
+	}
+
+	// a dry run shares the db with other readers
+	openDB(*dryRun)
+	defer db.Close()
+
+	result, err := importRecords(read, bufio.NewReader(in), ImportOptions{
+		Conflict: *conflict,
+		Chunk:    *chunk,
+		DryRun:   *dryRun,
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	verb := "imported"
+	if result.DryRun {
+		verb = "would import"
+	}
+	fmt.Printf("%d records %s: %d created, %d overwritten, %d skipped, %d unchanged; %d buckets created\n",
+		result.Records, verb, result.Created, result.Overwritten, result.Skipped, result.Unchanged, result.Buckets)
+	for _, c := range result.Conflicts {
+		fmt.Println("conflict:", c)
+	}
+}
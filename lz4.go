@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// lz4Compressor handles the LZ4 frame format, as written by the lz4 tool
+// and most libraries. Blocks it writes are independent, at most 64 KiB
+// each, without checksums.
+type lz4Compressor struct{}
+
+const (
+	lz4Magic          = 0x184d2204
+	lz4BlockSize      = 64 << 10
+	lz4MinMatch       = 4
+	lz4LastLiterals   = 5  // a block ends with at least that many literals
+	lz4MatchLimit     = 12 // and its last match starts before that
+	lz4UncompressedFl = 1 << 31
+)
+
+var errLZ4Corrupt = errors.New("lz4: corrupt data")
+
+func (lz4Compressor) Compress(b []byte) ([]byte, error) {
+	// version 01, independent blocks; 64 KiB maximum block size
+	descriptor := []byte{0x60, 0x40}
+
+	out := make([]byte, 4, len(b)+16)
+	binary.LittleEndian.PutUint32(out, lz4Magic)
+	out = append(out, descriptor...)
+	out = append(out, byte(xxh32(descriptor, 0)>>8))
+
+	for len(b) > 0 {
+		n := len(b)
+		if n > lz4BlockSize {
+			n = lz4BlockSize
+		}
+
+		block := lz4CompressBlock(b[:n])
+		size := uint32(len(block))
+		if len(block) >= n {
+			block, size = b[:n], uint32(n)|lz4UncompressedFl
+		}
+
+		out = appendUint32(out, size)
+		out = append(out, block...)
+		b = b[n:]
+	}
+	return appendUint32(out, 0), nil
+}
+
+func (lz4Compressor) Decompress(b []byte) ([]byte, error) {
+	var out []byte
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return nil, errLZ4Corrupt
+		}
+		magic := binary.LittleEndian.Uint32(b)
+
+		// skippable frames
+		if magic&0xfffffff0 == 0x184d2a50 {
+			if len(b) < 8 {
+				return nil, errLZ4Corrupt
+			}
+			n := uint64(binary.LittleEndian.Uint32(b[4:]))
+			if n > uint64(len(b)-8) {
+				return nil, errLZ4Corrupt
+			}
+			b = b[8+n:]
+			continue
+		}
+		if magic != lz4Magic {
+			return nil, errors.New("lz4: not an LZ4 frame")
+		}
+
+		var err error
+		if out, b, err = lz4DecompressFrame(out, b[4:]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// lz4DecompressFrame appends the content of the frame at the start of b,
+// after its magic number, to out and returns the rest of b.
+func lz4DecompressFrame(out, b []byte) ([]byte, []byte, error) {
+	if len(b) < 3 {
+		return nil, nil, errLZ4Corrupt
+	}
+	flags := b[0]
+	if flags>>6 != 1 {
+		return nil, nil, errors.New("lz4: unsupported frame version")
+	}
+	blockChecksum, contentSize, contentChecksum, dictID := flags&0x10 != 0, flags&0x08 != 0, flags&0x04 != 0, flags&0x01 != 0
+	if dictID {
+		return nil, nil, errors.New("lz4: dictionaries are not supported")
+	}
+
+	n := 2
+	if contentSize {
+		n += 8
+	}
+	if len(b) < n+1 {
+		return nil, nil, errLZ4Corrupt
+	}
+	if byte(xxh32(b[:n], 0)>>8) != b[n] {
+		return nil, nil, errors.New("lz4: frame header checksum mismatch")
+	}
+	b = b[n+1:]
+
+	start := len(out)
+	for {
+		if len(b) < 4 {
+			return nil, nil, errLZ4Corrupt
+		}
+		size := binary.LittleEndian.Uint32(b)
+		b = b[4:]
+		if size == 0 {
+			break
+		}
+
+		n := int(size &^ lz4UncompressedFl)
+		if n > len(b) {
+			return nil, nil, errLZ4Corrupt
+		}
+		if size&lz4UncompressedFl != 0 {
+			if len(out)+n > maxDecompressed {
+				return nil, nil, errTooLarge
+			}
+			out = append(out, b[:n]...)
+		} else {
+			var err error
+			if out, err = lz4DecompressBlock(out, b[:n]); err != nil {
+				return nil, nil, err
+			}
+		}
+		b = b[n:]
+
+		if blockChecksum {
+			if len(b) < 4 {
+				return nil, nil, errLZ4Corrupt
+			}
+			b = b[4:]
+		}
+	}
+
+	if contentChecksum {
+		if len(b) < 4 {
+			return nil, nil, errLZ4Corrupt
+		}
+		if binary.LittleEndian.Uint32(b) != xxh32(out[start:], 0) {
+			return nil, nil, errors.New("lz4: content checksum mismatch")
+		}
+		b = b[4:]
+	}
+	return out, b, nil
+}
+
+// lz4DecompressBlock appends the content of a compressed block to out.
+// Matches may reach back into earlier blocks, which are already in out.
+func lz4DecompressBlock(out, b []byte) ([]byte, error) {
+	// length reads the extra bytes of a literal or match length
+	length := func(n int) (int, error) {
+		if n != 15 {
+			return n, nil
+		}
+		for {
+			if len(b) == 0 {
+				return 0, errLZ4Corrupt
+			}
+			c := b[0]
+			b = b[1:]
+			n += int(c)
+			if c != 255 {
+				return n, nil
+			}
+		}
+	}
+
+	for len(b) > 0 {
+		token := b[0]
+		b = b[1:]
+
+		literals, err := length(int(token >> 4))
+		if err != nil {
+			return nil, err
+		}
+		if literals > len(b) {
+			return nil, errLZ4Corrupt
+		}
+		if len(out)+literals > maxDecompressed {
+			return nil, errTooLarge
+		}
+		out = append(out, b[:literals]...)
+		b = b[literals:]
+
+		// the last sequence has no match
+		if len(b) == 0 {
+			break
+		}
+
+		if len(b) < 2 {
+			return nil, errLZ4Corrupt
+		}
+		offset := int(binary.LittleEndian.Uint16(b))
+		b = b[2:]
+		if offset == 0 || offset > len(out) {
+			return nil, errLZ4Corrupt
+		}
+
+		match, err := length(int(token & 15))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(out)+match+lz4MinMatch > maxDecompressed {
+			return nil, errTooLarge
+		}
+
+		// byte by byte, the match may overlap what it appends
+		pos := len(out) - offset
+		for i := 0; i < match+lz4MinMatch; i++ {
+			out = append(out, out[pos+i])
+		}
+	}
+	return out, nil
+}
+
+// lz4CompressBlock compresses b greedily with a hash table of the last
+// position of every 4 byte sequence.
+func lz4CompressBlock(b []byte) []byte {
+	var (
+		table  [1 << 14]int32 // positions + 1
+		out    []byte
+		anchor int
+	)
+
+	for i := 0; i+lz4MatchLimit <= len(b); {
+		seq := binary.LittleEndian.Uint32(b[i:])
+		h := seq * 2654435761 >> 18
+		ref := int(table[h]) - 1
+		table[h] = int32(i + 1)
+
+		if ref < 0 || i-ref > 0xffff || binary.LittleEndian.Uint32(b[ref:]) != seq {
+			i++
+			continue
+		}
+
+		n := lz4MinMatch
+		for i+n < len(b)-lz4LastLiterals && b[ref+n] == b[i+n] {
+			n++
+		}
+
+		out = lz4Sequence(out, b[anchor:i], i-ref, n)
+		i += n
+		anchor = i
+	}
+	return lz4Sequence(out, b[anchor:], 0, 0)
+}
+
+// lz4Sequence appends a sequence of literals followed by a match of n bytes
+// at offset, or by nothing if n is 0.
+func lz4Sequence(out, literals []byte, offset, n int) []byte {
+	token := byte(15 << 4)
+	if len(literals) < 15 {
+		token = byte(len(literals) << 4)
+	}
+	if n > 0 {
+		if n-lz4MinMatch < 15 {
+			token |= byte(n - lz4MinMatch)
+		} else {
+			token |= 15
+		}
+	}
+	out = append(out, token)
+
+	out = lz4Length(out, len(literals))
+	out = append(out, literals...)
+	if n > 0 {
+		out = append(out, byte(offset), byte(offset>>8))
+		out = lz4Length(out, n-lz4MinMatch)
+	}
+	return out
+}
+
+// lz4Length appends the extra bytes of a length that does not fit the 4
+// bits of the token.
+func lz4Length(out []byte, n int) []byte {
+	if n < 15 {
+		return out
+	}
+	for n -= 15; n >= 255; n -= 255 {
+		out = append(out, 255)
+	}
+	return append(out, byte(n))
+}
+
+func appendUint32(b []byte, u uint32) []byte {
+	return append(b, byte(u), byte(u>>8), byte(u>>16), byte(u>>24))
+}
+
+// xxh32 is the 32 bit xxHash, which LZ4 frames use for checksums.
+func xxh32(b []byte, seed uint32) uint32 {
+	const (
+		p1 uint32 = 2654435761
+		p2 uint32 = 2246822519
+		p3 uint32 = 3266489917
+		p4 uint32 = 668265263
+		p5 uint32 = 374761393
+	)
+	round := func(acc, in uint32) uint32 {
+		return bits.RotateLeft32(acc+in*p2, 13) * p1
+	}
+
+	n := len(b)
+	var h uint32
+	if n >= 16 {
+		v1, v2, v3, v4 := seed+p1+p2, seed+p2, seed, seed-p1
+		for ; len(b) >= 16; b = b[16:] {
+			v1 = round(v1, binary.LittleEndian.Uint32(b))
+			v2 = round(v2, binary.LittleEndian.Uint32(b[4:]))
+			v3 = round(v3, binary.LittleEndian.Uint32(b[8:]))
+			v4 = round(v4, binary.LittleEndian.Uint32(b[12:]))
+		}
+		h = bits.RotateLeft32(v1, 1) + bits.RotateLeft32(v2, 7) + bits.RotateLeft32(v3, 12) + bits.RotateLeft32(v4, 18)
+	} else {
+		h = seed + p5
+	}
+
+	h += uint32(n)
+	for ; len(b) >= 4; b = b[4:] {
+		h += binary.LittleEndian.Uint32(b) * p3
+		h = bits.RotateLeft32(h, 17) * p4
+	}
+	for _, c := range b {
+		h += uint32(c) * p5
+		h = bits.RotateLeft32(h, 11) * p1
+	}
+
+	h ^= h >> 15
+	h *= p2
+	h ^= h >> 13
+	h *= p3
+	h ^= h >> 16
+	return h
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// lz4Vectors are frames written by the lz4 tool, v1.9.4.
+var lz4Vectors = []struct {
+	name, frame string
+	content     []byte
+}{
+	// lz4 -c
+	{"empty", "04224d186440a700000000055dcc02", nil},
+	{"literals", "04224d186440a70300008061626300000000ff53d132", []byte("abc")},
+	{"default", "04224d186440a7150000008f426f6c74475549200800ffffff03507447554920000000004a7c47bb",
+		bytes.Repeat([]byte("BoltGUI "), 100)},
+
+	// lz4 -c -BX --content-size: block checksums and the content size
+	{"checksums", "04224d187c4020030000000000002f150000008f426f6c74475549200800ffffff035074475549209314af95000000004a7c47bb",
+		bytes.Repeat([]byte("BoltGUI "), 100)},
+
+	// lz4 -c -B4 -BD -BX: 64 KiB blocks linked to the previous ones
+	{"linked", "" +
+		"04224d185440ae24020000ffff0b302c312c322c332c342c352c362c372c382c392c31302c31312c31322c31332c31342c31352c31362c31" +
+		"372c31382c31392c32302c32312c32322c32332c32342c32352c32362c32372c32382c32392c33302c33312c33322c33332c33342c33352c" +
+		"33362c33372c33382c33392c34302c34312c34322c34332c34342c34352c34362c34372c34382c34392c35302c35312c35322c35332c3534" +
+		"2c35352c35362c35372c35382c35392c36302c36312c36322c36332c36342c36352c36362c36372c36382c36392c37302c37312c37322c37" +
+		"332c37342c37352c37362c37372c37382c37392c38302c38312c38322c38332c38342c38352c38362c38372c38382c38392c39302c39312c" +
+		"39322c39332c39342c39352c39362c1901ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffce502c32332c32e7" +
+		"268ed1190000000fc1ffffffffffffffffffffffffffffffff865033392c3430ffeff3930000000094e7d164",
+		lz4Counter(24000)},
+}
+
+// lz4Counter returns the numbers 0 to 96 separated by commas, repeated up
+// to n numbers.
+func lz4Counter(n int) []byte {
+	s := make([]string, n)
+	for i := range s {
+		s[i] = strconv.Itoa(i % 97)
+	}
+	return []byte(strings.Join(s, ","))
+}
+
+func TestLZ4Vectors(t *testing.T) {
+	for _, v := range lz4Vectors {
+		frame, _ := hex.DecodeString(v.frame)
+		b, err := lz4Compressor{}.Decompress(frame)
+		if err != nil || !bytes.Equal(b, v.content) {
+			t.Errorf("%s: Decompress = %q, %v, want %q", v.name, b, err, v.content)
+		}
+	}
+}
+
+func TestLZ4RoundTrip(t *testing.T) {
+	random := make([]byte, 100000)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	for _, b := range [][]byte{
+		nil,
+		[]byte("a"),
+		[]byte("abcabcabcabcabcabc"),
+		bytes.Repeat([]byte{0}, 1000),
+		lz4Counter(50000),
+		random,
+		append(lz4Counter(20000), random...),
+	} {
+		c, err := lz4Compressor{}.Compress(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := lz4Compressor{}.Decompress(c)
+		if err != nil || !bytes.Equal(d, b) {
+			t.Errorf("round trip of %d bytes: got %d bytes, %v", len(b), len(d), err)
+		}
+	}
+}
+
+func TestLZ4TooLarge(t *testing.T) {
+	out := make([]byte, maxDecompressed)
+	if _, err := lz4DecompressBlock(out, []byte{0x10, 'a'}); err != errTooLarge {
+		t.Errorf("literals past the limit: err = %v, want errTooLarge", err)
+	}
+}
+
+// The reference values of xxHash, which the lz4 tool also writes as the
+// content checksum of the vectors above.
+func TestXXH32(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		seed uint32
+		want uint32
+	}{
+		{"", 0, 0x02cc5d05},
+		{"a", 0, 0x550d7456},
+		{"abc", 0, 0x32d153ff},
+		{"Nobody inspects the spammish repetition", 0, 0xe2293b2f},
+	} {
+		if got := xxh32([]byte(test.in), test.seed); got != test.want {
+			t.Errorf("xxh32(%q, %d) = %08x, want %08x", test.in, test.seed, got, test.want)
+		}
+	}
+}
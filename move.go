@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/boltdb/bolt"
+)
+
+var (
+	errMoveIntoItself = errors.New("cannot move a bucket into itself")
+	errCopyOntoItself = errors.New("entries cannot be copied onto themselves")
+)
+
+// moveBucket moves the bucket at from, with everything nested in it, to the
+// new path to, which may be under another parent. Bolt cannot rename, so
+// the bucket is copied and the original deleted, in one transaction. With
+// parents, buckets missing on the way to to are created.
+func moveBucket(from, to BucketPath, parents bool) error {
+	if to.within(from) {
+		return fmt.Errorf("bucket %s: %w", from, errMoveIntoItself)
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		src, err := from.bucket(tx)
+		if err != nil {
+			return err
+		}
+
+		dst, err := createBucketAt(tx, to, parents)
+		if err != nil {
+			return fmt.Errorf("moving to %s: %w", to, err)
+		}
+		if err := copyBucket(dst, src); err != nil {
+			return err
+		}
+
+		return deleteBucketAt(tx, from)
+	})
+}
+
+// copyBucket copies the entries, nested buckets and sequence of src to the
+// empty bucket dst.
+func copyBucket(dst, src *bolt.Bucket) error {
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	return src.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return dst.Put(k, v)
+		}
+
+		nested, err := dst.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+		return copyBucket(nested, src.Bucket(k))
+	})
+}
+
+// within reports whether p is the path of prefix or of a bucket nested in
+// it.
+func (p BucketPath) within(prefix BucketPath) bool {
+	if len(p) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if !bytes.Equal(p[i], prefix[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func moveBucketHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	from, err := parseBucketPath(r.FormValue("bucket"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := parseBucketPath(r.FormValue("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("to: "+err.Error()))
+		return
+	}
+
+	if err := moveBucket(from, to, r.FormValue("parents") == "true"); err != nil {
+		writeError(w, errorStatus(err), err)
+	}
+}
+
+var errKeyNotFound = errors.New("key not found")
+
+// renameEntry moves the value of key from to key to in the bucket at path,
+// replacing it with value unless that is nil. A value already at to is
+// only replaced with overwrite.
+func renameEntry(path BucketPath, from, to, value []byte, overwrite bool) (Entry, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		buck, err := path.bucket(tx)
+		if err != nil {
+			return err
+		}
+
+		old := buck.Get(from)
+		if old == nil {
+			return fmt.Errorf("key %s: %w", printable(from), errKeyNotFound)
+		}
+		if value == nil {
+			value = append([]byte{}, old...)
+		}
+
+		if !bytes.Equal(from, to) {
+			if !overwrite && buck.Get(to) != nil {
+				return fmt.Errorf("key %s: %w", printable(to), errKeyExists)
+			}
+			if err := buck.Delete(from); err != nil {
+				return err
+			}
+		}
+		return buck.Put(to, value)
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return decodeEntry(path, to, value), nil
+}
+
+func renameEntryHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	path, err := parseBucketPath(r.FormValue("bucket"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	from, err := formKey(r, path, "key", "rawKey")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := formKey(r, path, "newKey", "rawNewKey")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// the value is kept unless a new one is sent along
+	var value []byte
+	if _, ok := r.Form["rawValue"]; ok {
+		value, err = formBytes(r, "value", "rawValue")
+	} else if _, ok := r.Form["value"]; ok {
+		value, err = encodeEntry(path, r.FormValue("value"), r.FormValue("format"))
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	entry, err := renameEntry(path, from, to, value, r.FormValue("overwrite") == "true")
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, entry)
+}
+
+// copyEntries copies the entries with the given keys from the bucket at from
+// to the one at to, which with parents is created as needed, and deletes
+// them from from with move. Values already in to are only replaced with
+// overwrite. It returns the number of entries copied.
+func copyEntries(from BucketPath, keys [][]byte, to BucketPath, move, overwrite, parents bool) (int, error) {
+	if len(to) == len(from) && to.within(from) {
+		return 0, errCopyOntoItself
+	}
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		src, err := from.bucket(tx)
+		if err != nil {
+			return err
+		}
+
+		var dst *bolt.Bucket
+		if parents {
+			dst, err = to.createBucket(tx)
+		} else {
+			dst, err = to.bucket(tx)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			v := src.Get(k)
+			if v == nil {
+				return fmt.Errorf("bucket %s: key %s: %w", from, printable(k), errKeyNotFound)
+			}
+			if !overwrite && dst.Get(k) != nil {
+				return fmt.Errorf("bucket %s: key %s: %w", to, printable(k), errKeyExists)
+			}
+
+			if err := dst.Put(k, v); err != nil {
+				return err
+			}
+			if move {
+				if err := src.Delete(k); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
+
+func copyEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	from, err := parseBucketPath(r.FormValue("bucket"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := parseBucketPath(r.FormValue("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("to: "+err.Error()))
+		return
+	}
+
+	// keys are base64 encoded, like bucket names
+	var keys [][]byte
+	if err := json.Unmarshal([]byte(r.FormValue("keys")), &keys); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid keys: %v", err))
+		return
+	}
+
+	n, err := copyEntries(from, keys, to,
+		r.FormValue("move") == "true",
+		r.FormValue("overwrite") == "true",
+		r.FormValue("parents") == "true")
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, map[string]int{"copied": n})
+}
@@ -0,0 +1,1069 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// msgpackCodec shows MessagePack values as JSON without losing anything
+// JSON cannot express, so that editing a value only changes what was
+// edited. Values JSON has a type for are plain JSON as long as they use the
+// shortest encoding; everything else is an object of $ annotations:
+//
+//	{"$int": 1, "$fmt": "uint32"}       integer in a wider format
+//	{"$float32": 1.5}                    32 bit float, 64 bit ones are
+//	                                     numbers with a fraction or exponent
+//	{"$float64": "NaN"}                  also "+Inf" and "-Inf", or the bits
+//	                                     in hex for NaNs with a payload
+//	{"$str": "a", "$fmt": "str16"}       string in a wider format
+//	{"$strb64": "/w=="}                  string that is not valid UTF-8
+//	{"$bin": "AQI="}                     binary
+//	{"$ext": 5, "$data": "AQI="}         extension type
+//	{"$time": "2020-01-02T03:04:05Z"}    timestamp extension
+//	{"$map": [[1, "a"], [2, "b"]]}       map with keys other than strings
+//	{"$array": [1, 2], "$fmt": "array16"}
+//
+// Maps keep the order of their keys.
+type msgpackCodec struct{}
+
+func (msgpackCodec) Decode(b []byte) (string, error) {
+	d := msgpackDecoder{b: b}
+
+	var buf bytes.Buffer
+	if err := d.value(&buf); err != nil {
+		return "", err
+	}
+	if len(d.b) > 0 {
+		return "", fmt.Errorf("msgpack: %d trailing bytes", len(d.b))
+	}
+	return d.render(buf.Bytes()), nil
+}
+
+func (msgpackCodec) Encode(s string) ([]byte, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	var e msgpackEncoder
+	if err := e.value(dec); err != nil {
+		return nil, fmt.Errorf("value is not valid msgpack JSON: %v", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("value is not valid msgpack JSON: data after the value")
+	}
+	return e.buf.Bytes(), nil
+}
+
+// msgpackFormat describes a format with a type byte of its own: its code
+// and the size of the length or integer following it.
+type msgpackFormat struct {
+	code byte
+	size int
+}
+
+// msgpackFormats holds the formats by their name in the MessagePack
+// specification, as used in "$fmt".
+var msgpackFormats = map[string]msgpackFormat{
+	"bin8": {0xc4, 1}, "bin16": {0xc5, 2}, "bin32": {0xc6, 4},
+	"ext8": {0xc7, 1}, "ext16": {0xc8, 2}, "ext32": {0xc9, 4},
+	"uint8": {0xcc, 1}, "uint16": {0xcd, 2}, "uint32": {0xce, 4}, "uint64": {0xcf, 8},
+	"int8": {0xd0, 1}, "int16": {0xd1, 2}, "int32": {0xd2, 4}, "int64": {0xd3, 8},
+	"fixext1": {0xd4, 0}, "fixext2": {0xd5, 0}, "fixext4": {0xd6, 0}, "fixext8": {0xd7, 0}, "fixext16": {0xd8, 0},
+	"str8": {0xd9, 1}, "str16": {0xda, 2}, "str32": {0xdb, 4},
+	"array16": {0xdc, 2}, "array32": {0xdd, 4},
+	"map16": {0xde, 2}, "map32": {0xdf, 4},
+}
+
+// msgpackFormatNames maps the codes of msgpackFormats back to their names.
+var msgpackFormatNames = func() map[byte]string {
+	names := make(map[byte]string, len(msgpackFormats))
+	for name, f := range msgpackFormats {
+		names[f.code] = name
+	}
+	return names
+}()
+
+// msgpackMaxDepth bounds the nesting of arrays and maps.
+const msgpackMaxDepth = 100
+
+// msgpackDecoder renders MessagePack as the annotated JSON described at
+// msgpackCodec. Whether a map is shown as an object is only known once all
+// of it was read, so the output is written without the punctuation of maps,
+// which seps inserts by offset once everything was read.
+type msgpackDecoder struct {
+	b     []byte
+	depth int
+	seps  []msgpackSep
+}
+
+// msgpackSep is the punctuation of map m at offset off of the output.
+type msgpackSep struct {
+	off  int
+	m    *msgpackMap
+	kind int
+}
+
+// The punctuation of a map: before it, between a key and its value,
+// between pairs and after it.
+const (
+	sepOpen = iota
+	sepColon
+	sepComma
+	sepClose
+)
+
+// msgpackMap is a map as far as its punctuation goes.
+type msgpackMap struct {
+	n      int
+	format string
+	object bool
+}
+
+func (m *msgpackMap) sep(kind int) string {
+	if m.object {
+		return [...]string{"{", ":", ",", "}"}[kind]
+	}
+
+	switch kind {
+	case sepOpen:
+		if m.n == 0 {
+			return `{"$map":[`
+		}
+		return `{"$map":[[`
+	case sepColon:
+		return ","
+	case sepComma:
+		return "],["
+	}
+	close := "]]"
+	if m.n == 0 {
+		close = "]"
+	}
+	if m.format != minFormat("map", m.n) {
+		close += fmt.Sprintf(`,"$fmt":%q`, m.format)
+	}
+	return close + "}"
+}
+
+// render returns the output b with the punctuation of maps inserted.
+func (d *msgpackDecoder) render(b []byte) string {
+	var out strings.Builder
+	out.Grow(len(b) + 2*len(d.seps))
+	last := 0
+	for _, sep := range d.seps {
+		out.Write(b[last:sep.off])
+		out.WriteString(sep.m.sep(sep.kind))
+		last = sep.off
+	}
+	out.Write(b[last:])
+	return out.String()
+}
+
+var (
+	errMsgpackShort = errors.New("msgpack: unexpected end of data")
+	errMsgpackDepth = fmt.Errorf("msgpack: nested deeper than %d levels", msgpackMaxDepth)
+)
+
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || n > len(d.b) {
+		return nil, errMsgpackShort
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b, nil
+}
+
+// uint reads a big endian unsigned integer of n bytes.
+func (d *msgpackDecoder) uint(n int) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+func (d *msgpackDecoder) value(buf *bytes.Buffer) error {
+	if len(d.b) == 0 {
+		return errMsgpackShort
+	}
+	c := d.b[0]
+	d.b = d.b[1:]
+
+	switch {
+	case c <= 0x7f:
+		buf.WriteString(strconv.Itoa(int(c)))
+		return nil
+	case c >= 0xe0:
+		buf.WriteString(strconv.Itoa(int(int8(c))))
+		return nil
+	case c >= 0x80 && c <= 0x8f:
+		return d.mapValue(buf, int(c&0x0f), "fixmap")
+	case c >= 0x90 && c <= 0x9f:
+		return d.array(buf, int(c&0x0f), "fixarray")
+	case c >= 0xa0 && c <= 0xbf:
+		return d.str(buf, int(c&0x1f), "fixstr")
+	}
+
+	format := msgpackFormatNames[c]
+	size := msgpackFormats[format].size
+	switch c {
+	case 0xc0:
+		buf.WriteString("null")
+	case 0xc2:
+		buf.WriteString("false")
+	case 0xc3:
+		buf.WriteString("true")
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(size)
+		if err != nil {
+			return err
+		}
+		b, err := d.next(int(n))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, `{"$bin":%s`, quote(base64.StdEncoding.EncodeToString(b)))
+		if format != minFormat("bin", len(b)) {
+			fmt.Fprintf(buf, `,"$fmt":%q`, format)
+		}
+		buf.WriteByte('}')
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(size)
+		if err != nil {
+			return err
+		}
+		return d.ext(buf, int(n), format)
+	case 0xca:
+		u, err := d.uint(4)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(`{"$float32":`)
+		if f := math.Float32frombits(uint32(u)); f != f && uint32(u) != math.Float32bits(float32(math.NaN())) {
+			fmt.Fprintf(buf, `"0x%08x"`, u)
+		} else {
+			writeFloat(buf, float64(f), 32)
+		}
+		buf.WriteByte('}')
+	case 0xcb:
+		u, err := d.uint(8)
+		if err != nil {
+			return err
+		}
+		f := math.Float64frombits(u)
+		if math.IsNaN(f) && u != math.Float64bits(math.NaN()) {
+			fmt.Fprintf(buf, `{"$float64":"0x%016x"}`, u)
+		} else if math.IsNaN(f) || math.IsInf(f, 0) {
+			buf.WriteString(`{"$float64":`)
+			writeFloat(buf, f, 64)
+			buf.WriteByte('}')
+		} else {
+			writeFloat(buf, f, 64)
+		}
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(size)
+		if err != nil {
+			return err
+		}
+		writeInt(buf, strconv.FormatUint(u, 10), format, minUintFormat(u))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		u, err := d.uint(size)
+		if err != nil {
+			return err
+		}
+		// sign extend from size bytes
+		shift := uint(64 - 8*size)
+		i := int64(u<<shift) >> shift
+		writeInt(buf, strconv.FormatInt(i, 10), format, minIntFormat(i))
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(buf, 1<<(c-0xd4), format)
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(size)
+		if err != nil {
+			return err
+		}
+		return d.str(buf, int(n), format)
+	case 0xdc, 0xdd:
+		n, err := d.uint(size)
+		if err != nil {
+			return err
+		}
+		return d.array(buf, int(n), format)
+	case 0xde, 0xdf:
+		n, err := d.uint(size)
+		if err != nil {
+			return err
+		}
+		return d.mapValue(buf, int(n), format)
+	default:
+		return fmt.Errorf("msgpack: invalid format byte 0x%02x", c)
+	}
+	return nil
+}
+
+func (d *msgpackDecoder) str(buf *bytes.Buffer, n int, format string) error {
+	b, err := d.next(n)
+	if err != nil {
+		return err
+	}
+
+	canonical := format == minFormat("str", n)
+	switch {
+	case !utf8.Valid(b):
+		fmt.Fprintf(buf, `{"$strb64":%s`, quote(base64.StdEncoding.EncodeToString(b)))
+	case canonical:
+		buf.WriteString(quote(string(b)))
+		return nil
+	default:
+		fmt.Fprintf(buf, `{"$str":%s`, quote(string(b)))
+	}
+	if !canonical {
+		fmt.Fprintf(buf, `,"$fmt":%q`, format)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func (d *msgpackDecoder) array(buf *bytes.Buffer, n int, format string) error {
+	// every element takes at least a byte
+	if n > len(d.b) {
+		return errMsgpackShort
+	}
+	if d.depth++; d.depth > msgpackMaxDepth {
+		return errMsgpackDepth
+	}
+	defer func() { d.depth-- }()
+
+	canonical := format == minFormat("array", n)
+	if !canonical {
+		buf.WriteString(`{"$array":`)
+	}
+
+	buf.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := d.value(buf); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+
+	if !canonical {
+		fmt.Fprintf(buf, `,"$fmt":%q}`, format)
+	}
+	return nil
+}
+
+// mapValue renders a map as a JSON object when its keys allow it, and as a
+// list of pairs otherwise.
+func (d *msgpackDecoder) mapValue(buf *bytes.Buffer, n int, format string) error {
+	if 2*n > len(d.b) {
+		return errMsgpackShort
+	}
+	if d.depth++; d.depth > msgpackMaxDepth {
+		return errMsgpackDepth
+	}
+	defer func() { d.depth-- }()
+
+	m := &msgpackMap{n: n, format: format, object: format == minFormat("map", n)}
+	sep := func(kind int) {
+		d.seps = append(d.seps, msgpackSep{buf.Len(), m, kind})
+	}
+
+	seen := make(map[string]bool, n)
+	sep(sepOpen)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sep(sepComma)
+		}
+
+		// plain string keys; those starting with $ would read as
+		// annotations. A string is written without punctuation of maps.
+		start := buf.Len()
+		str := len(d.b) > 0 && (d.b[0] >= 0xa0 && d.b[0] <= 0xbf || d.b[0] >= 0xd9 && d.b[0] <= 0xdb)
+		if err := d.value(buf); err != nil {
+			return err
+		}
+		if !str {
+			m.object = false
+		} else if m.object {
+			k := string(buf.Bytes()[start:])
+			if !strings.HasPrefix(k, `"`) || strings.HasPrefix(k, `"$`) || seen[k] {
+				m.object = false
+			}
+			seen[k] = true
+		}
+
+		sep(sepColon)
+		if err := d.value(buf); err != nil {
+			return err
+		}
+	}
+	sep(sepClose)
+	return nil
+}
+
+func (d *msgpackDecoder) ext(buf *bytes.Buffer, n int, format string) error {
+	t, err := d.next(1)
+	if err != nil {
+		return err
+	}
+	b, err := d.next(n)
+	if err != nil {
+		return err
+	}
+
+	// timestamps RFC 3339 cannot hold, or with a header the encoder would
+	// not write for their layout, stay plain extensions
+	if int8(t[0]) == -1 && format == timestampLayouts[n] {
+		if ts, ok := decodeTimestamp(b); ok && ts.Year() >= 0 && ts.Year() <= 9999 {
+			fmt.Fprintf(buf, `{"$time":%q`, ts.Format(time.RFC3339Nano))
+			if format != timestampFormat(ts) {
+				fmt.Fprintf(buf, `,"$fmt":%q`, format)
+			}
+			buf.WriteByte('}')
+			return nil
+		}
+	}
+
+	fmt.Fprintf(buf, `{"$ext":%d,"$data":%s`, int8(t[0]), quote(base64.StdEncoding.EncodeToString(b)))
+	if format != minFormat("ext", n) {
+		fmt.Fprintf(buf, `,"$fmt":%q`, format)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// timestampLayouts maps the lengths of the timestamp layouts to the format
+// of their header.
+var timestampLayouts = map[int]string{4: "fixext4", 8: "fixext8", 12: "ext8"}
+
+// decodeTimestamp decodes the data of the timestamp extension type in any
+// of its three layouts.
+func decodeTimestamp(b []byte) (time.Time, bool) {
+	var sec, nsec int64
+	switch len(b) {
+	case 4:
+		sec = int64(binary.BigEndian.Uint32(b))
+	case 8:
+		u := binary.BigEndian.Uint64(b)
+		sec, nsec = int64(u&(1<<34-1)), int64(u>>34)
+	case 12:
+		nsec = int64(binary.BigEndian.Uint32(b))
+		sec = int64(binary.BigEndian.Uint64(b[4:]))
+	default:
+		return time.Time{}, false
+	}
+	if nsec > 999999999 {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, nsec).UTC(), true
+}
+
+// timestampFormat returns the format of the shortest layout holding t.
+func timestampFormat(t time.Time) string {
+	switch sec := t.Unix(); {
+	case sec>>34 != 0:
+		return "ext8"
+	case t.Nanosecond() != 0 || sec>>32 != 0:
+		return "fixext8"
+	}
+	return "fixext4"
+}
+
+// minFormat returns the shortest format of kind ("str", "bin", "array",
+// "map" or "ext") for n elements.
+func minFormat(kind string, n int) string {
+	switch kind {
+	case "str":
+		if n < 32 {
+			return "fixstr"
+		}
+	case "array", "map":
+		if n < 16 {
+			return "fix" + kind
+		}
+		if n <= math.MaxUint16 {
+			return kind + "16"
+		}
+		return kind + "32"
+	case "ext":
+		switch n {
+		case 1, 2, 4, 8, 16:
+			return "fixext" + strconv.Itoa(n)
+		}
+	}
+
+	switch {
+	case n <= math.MaxUint8:
+		return kind + "8"
+	case n <= math.MaxUint16:
+		return kind + "16"
+	}
+	return kind + "32"
+}
+
+func minUintFormat(u uint64) string {
+	switch {
+	case u <= 0x7f:
+		return "fixint"
+	case u <= math.MaxUint8:
+		return "uint8"
+	case u <= math.MaxUint16:
+		return "uint16"
+	case u <= math.MaxUint32:
+		return "uint32"
+	}
+	return "uint64"
+}
+
+func minIntFormat(i int64) string {
+	switch {
+	case i >= 0:
+		return minUintFormat(uint64(i))
+	case i >= -32:
+		return "fixint"
+	case i >= math.MinInt8:
+		return "int8"
+	case i >= math.MinInt16:
+		return "int16"
+	case i >= math.MinInt32:
+		return "int32"
+	}
+	return "int64"
+}
+
+func writeInt(buf *bytes.Buffer, n, format, canonical string) {
+	if format == canonical {
+		buf.WriteString(n)
+		return
+	}
+	fmt.Fprintf(buf, `{"$int":%s,"$fmt":%q}`, n, format)
+}
+
+// writeFloat writes f so that it reads back as a float: finite values
+// always have a fraction or an exponent, others are quoted.
+func writeFloat(buf *bytes.Buffer, f float64, bits int) {
+	switch {
+	case math.IsNaN(f):
+		buf.WriteString(`"NaN"`)
+		return
+	case math.IsInf(f, 1):
+		buf.WriteString(`"+Inf"`)
+		return
+	case math.IsInf(f, -1):
+		buf.WriteString(`"-Inf"`)
+		return
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	buf.WriteString(s)
+}
+
+// quote encodes s as a JSON string without escaping HTML characters.
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// msgpackEncoder writes the annotated JSON described at msgpackCodec back
+// as MessagePack.
+type msgpackEncoder struct {
+	buf   bytes.Buffer
+	depth int
+}
+
+// nested returns the encoder of the elements of an array or map, which are
+// one level deeper.
+func (e *msgpackEncoder) nested() (*msgpackEncoder, error) {
+	if e.depth >= msgpackMaxDepth {
+		return nil, errMsgpackDepth
+	}
+	return &msgpackEncoder{depth: e.depth + 1}, nil
+}
+
+func (e *msgpackEncoder) value(dec *json.Decoder) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	return e.token(dec, t)
+}
+
+func (e *msgpackEncoder) token(dec *json.Decoder, t json.Token) error {
+	switch t := t.(type) {
+	case nil:
+		e.buf.WriteByte(0xc0)
+	case bool:
+		if t {
+			e.buf.WriteByte(0xc3)
+		} else {
+			e.buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		if strings.ContainsAny(string(t), ".eE") {
+			f, err := t.Float64()
+			if err != nil {
+				return err
+			}
+			e.float(f, 64)
+			return nil
+		}
+		return e.integer(t, "")
+	case string:
+		return e.str([]byte(t), "")
+	case json.Delim:
+		if t == '[' {
+			return e.array(dec, "")
+		}
+		return e.object(dec)
+	}
+	return nil
+}
+
+// uint writes the low size bytes of u big endian.
+func (e *msgpackEncoder) uint(u uint64, size int) {
+	for i := size - 1; i >= 0; i-- {
+		e.buf.WriteByte(byte(u >> uint(8*i)))
+	}
+}
+
+func (e *msgpackEncoder) integer(n json.Number, format string) error {
+	var (
+		u   uint64
+		big bool // above the int64 range
+	)
+	i, err := n.Int64()
+	if err != nil {
+		if u, err = strconv.ParseUint(string(n), 10, 64); err != nil {
+			return fmt.Errorf("integer %s out of range", n)
+		}
+		big = true
+	} else {
+		u = uint64(i)
+	}
+
+	if format == "" {
+		format = "uint64"
+		if !big {
+			format = minIntFormat(i)
+		}
+	}
+	errFit := fmt.Errorf("%s does not fit %s", n, format)
+
+	if format == "fixint" {
+		if big || i < -32 || i > 0x7f {
+			return errFit
+		}
+		e.buf.WriteByte(byte(i))
+		return nil
+	}
+
+	f, ok := msgpackFormats[format]
+	if !ok || !strings.Contains(format, "int") {
+		return fmt.Errorf("invalid integer format %q", format)
+	}
+	bits := uint(8 * f.size)
+	if strings.HasPrefix(format, "uint") {
+		if !big && (i < 0 || bits < 64 && i >= 1<<bits) {
+			return errFit
+		}
+	} else if big || bits < 64 && (i < -1<<(bits-1) || i >= 1<<(bits-1)) {
+		return errFit
+	}
+
+	e.buf.WriteByte(f.code)
+	e.uint(u, f.size)
+	return nil
+}
+
+func (e *msgpackEncoder) float(f float64, bits int) {
+	if bits == 32 {
+		e.floatBits(uint64(math.Float32bits(float32(f))), 32)
+		return
+	}
+	e.floatBits(math.Float64bits(f), 64)
+}
+
+func (e *msgpackEncoder) floatBits(u uint64, bits int) {
+	if bits == 32 {
+		e.buf.WriteByte(0xca)
+		e.uint(u, 4)
+		return
+	}
+	e.buf.WriteByte(0xcb)
+	e.uint(u, 8)
+}
+
+// header writes the header of a str, bin, array, map or ext of kind holding
+// n bytes or elements, in format or else the shortest one.
+func (e *msgpackEncoder) header(kind string, n int, format string) error {
+	if format == "" {
+		format = minFormat(kind, n)
+	}
+	if !strings.HasPrefix(strings.TrimPrefix(format, "fix"), kind) {
+		return fmt.Errorf("invalid %s format %q", kind, format)
+	}
+	errFit := fmt.Errorf("%d elements do not fit %s", n, format)
+
+	var fix byte
+	switch format {
+	case "fixstr":
+		if n >= 32 {
+			return errFit
+		}
+		fix = 0xa0
+	case "fixarray":
+		fix = 0x90
+	case "fixmap":
+		fix = 0x80
+	}
+	if fix != 0 {
+		if n >= 16 && fix != 0xa0 {
+			return errFit
+		}
+		e.buf.WriteByte(fix | byte(n))
+		return nil
+	}
+
+	f, ok := msgpackFormats[format]
+	if !ok {
+		return fmt.Errorf("invalid %s format %q", kind, format)
+	}
+	if f.size == 0 {
+		// fixext, the size is in the name
+		if strconv.Itoa(n) != strings.TrimPrefix(format, "fixext") {
+			return errFit
+		}
+	} else if uint64(n) >= 1<<uint(8*f.size) {
+		return errFit
+	}
+
+	e.buf.WriteByte(f.code)
+	e.uint(uint64(n), f.size)
+	return nil
+}
+
+func (e *msgpackEncoder) str(b []byte, format string) error {
+	if err := e.header("str", len(b), format); err != nil {
+		return err
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+func (e *msgpackEncoder) bin(b []byte, format string) error {
+	if err := e.header("bin", len(b), format); err != nil {
+		return err
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+func (e *msgpackEncoder) ext(t int8, data []byte, format string) error {
+	if err := e.header("ext", len(data), format); err != nil {
+		return err
+	}
+	e.buf.WriteByte(byte(t))
+	e.buf.Write(data)
+	return nil
+}
+
+func (e *msgpackEncoder) timestamp(t time.Time, format string) error {
+	if t.Year() < 0 || t.Year() > 9999 {
+		return fmt.Errorf("%v is out of range", t)
+	}
+	if format == "" {
+		format = timestampFormat(t)
+	}
+
+	sec, nsec := t.Unix(), uint64(t.Nanosecond())
+	data := make([]byte, 12)
+	switch format {
+	case "fixext4":
+		if nsec != 0 || sec < 0 || sec>>32 != 0 {
+			return fmt.Errorf("%s does not fit %s", t.Format(time.RFC3339Nano), format)
+		}
+		binary.BigEndian.PutUint32(data, uint32(sec))
+		data = data[:4]
+	case "fixext8":
+		if sec < 0 || sec>>34 != 0 {
+			return fmt.Errorf("%s does not fit %s", t.Format(time.RFC3339Nano), format)
+		}
+		binary.BigEndian.PutUint64(data, nsec<<34|uint64(sec))
+		data = data[:8]
+	case "ext8":
+		binary.BigEndian.PutUint32(data, uint32(nsec))
+		binary.BigEndian.PutUint64(data[4:], uint64(sec))
+	default:
+		return fmt.Errorf("invalid timestamp format %q", format)
+	}
+	return e.ext(-1, data, format)
+}
+
+// array writes the elements up to the closing bracket, the opening one
+// having been read.
+func (e *msgpackEncoder) array(dec *json.Decoder, format string) error {
+	elems, err := e.nested()
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if t == json.Delim(']') {
+			break
+		}
+		if err := elems.token(dec, t); err != nil {
+			return err
+		}
+		n++
+	}
+
+	if err := e.header("array", n, format); err != nil {
+		return err
+	}
+	e.buf.Write(elems.buf.Bytes())
+	return nil
+}
+
+// object writes a map, or the value annotated by an object with $ keys.
+// The opening brace has been read.
+func (e *msgpackEncoder) object(dec *json.Decoder) error {
+	pairs, err := e.nested()
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if t == json.Delim('}') {
+			break
+		}
+
+		key := t.(string)
+		if strings.HasPrefix(key, "$") {
+			if n > 0 {
+				return fmt.Errorf("key %q mixed with plain keys, use $map", key)
+			}
+			return e.annotated(dec, key)
+		}
+
+		if err := pairs.str([]byte(key), ""); err != nil {
+			return err
+		}
+		if err := pairs.value(dec); err != nil {
+			return err
+		}
+		n++
+	}
+
+	if err := e.header("map", n, ""); err != nil {
+		return err
+	}
+	e.buf.Write(pairs.buf.Bytes())
+	return nil
+}
+
+// annotated writes the value described by the $ keys of an object, the
+// first of which, key, has been read.
+func (e *msgpackEncoder) annotated(dec *json.Decoder, key string) error {
+	fields := make(map[string]json.RawMessage)
+	for {
+		if !strings.HasPrefix(key, "$") {
+			return fmt.Errorf("key %q mixed with annotations", key)
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		fields[key] = raw
+
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if t == json.Delim('}') {
+			break
+		}
+		key = t.(string)
+	}
+
+	var format string
+	if raw, ok := fields["$fmt"]; ok {
+		if err := json.Unmarshal(raw, &format); err != nil {
+			return fmt.Errorf("$fmt: %v", err)
+		}
+		delete(fields, "$fmt")
+	}
+
+	var data []byte
+	if raw, ok := fields["$data"]; ok {
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return fmt.Errorf("$data: %v", err)
+		}
+		delete(fields, "$data")
+		if _, ok := fields["$ext"]; !ok {
+			return errors.New("$data without $ext")
+		}
+	}
+
+	if len(fields) != 1 {
+		return errors.New("an annotation needs exactly one of $int, $float32, $float64, $str, $strb64, $bin, $ext, $time, $map and $array")
+	}
+	for key, raw := range fields {
+		return e.annotation(key, raw, format, data)
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) annotation(key string, raw json.RawMessage, format string, data []byte) error {
+	sub := json.NewDecoder(bytes.NewReader(raw))
+	sub.UseNumber()
+
+	switch key {
+	case "$int":
+		var n json.Number
+		if err := sub.Decode(&n); err != nil {
+			return fmt.Errorf("$int: %v", err)
+		}
+		return e.integer(n, format)
+	case "$float32", "$float64":
+		bits := 64
+		if key == "$float32" {
+			bits = 32
+		}
+
+		// non-finite values and bits are quoted
+		s := string(raw)
+		json.Unmarshal(raw, &s)
+		if strings.HasPrefix(s, "0x") {
+			u, err := strconv.ParseUint(s[2:], 16, bits)
+			if err != nil {
+				return fmt.Errorf("%s: invalid bits %s", key, raw)
+			}
+			e.floatBits(u, bits)
+			return nil
+		}
+		f, err := strconv.ParseFloat(s, bits)
+		if err != nil {
+			return fmt.Errorf("%s: invalid float %s", key, raw)
+		}
+		e.float(f, bits)
+		return nil
+	case "$str":
+		var s string
+		if err := sub.Decode(&s); err != nil {
+			return fmt.Errorf("$str: %v", err)
+		}
+		return e.str([]byte(s), format)
+	case "$strb64", "$bin":
+		var b []byte
+		if err := sub.Decode(&b); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		if key == "$bin" {
+			return e.bin(b, format)
+		}
+		return e.str(b, format)
+	case "$ext":
+		var t int8
+		if err := sub.Decode(&t); err != nil {
+			return fmt.Errorf("$ext: %v", err)
+		}
+		return e.ext(t, data, format)
+	case "$time":
+		var s string
+		if err := sub.Decode(&s); err != nil {
+			return fmt.Errorf("$time: %v", err)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("$time: %v", err)
+		}
+		return e.timestamp(t, format)
+	case "$array":
+		if t, err := sub.Token(); err != nil || t != json.Delim('[') {
+			return errors.New("$array must be an array")
+		}
+		return e.array(sub, format)
+	case "$map":
+		return e.pairs(sub, format)
+	}
+	return fmt.Errorf("unknown annotation %s", key)
+}
+
+// pairs writes a map given as a list of [key, value] pairs.
+func (e *msgpackEncoder) pairs(dec *json.Decoder, format string) error {
+	errPairs := errors.New("$map must be a list of [key, value] pairs")
+	if t, err := dec.Token(); err != nil || t != json.Delim('[') {
+		return errPairs
+	}
+
+	pairs, err := e.nested()
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if t == json.Delim(']') {
+			break
+		}
+		if t != json.Delim('[') {
+			return errPairs
+		}
+
+		if err := pairs.value(dec); err != nil {
+			return err
+		}
+		if err := pairs.value(dec); err != nil {
+			return err
+		}
+		if t, err := dec.Token(); err != nil || t != json.Delim(']') {
+			return errPairs
+		}
+		n++
+	}
+
+	if err := e.header("map", n, format); err != nil {
+		return err
+	}
+	e.buf.Write(pairs.buf.Bytes())
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+var msgpackTests = []struct {
+	hex, json string
+}{
+	{"01", `1`},
+	{"ff", `-1`},
+	{"cc01", `{"$int":1,"$fmt":"uint8"}`},
+	{"d0ff", `{"$int":-1,"$fmt":"int8"}`},
+	{"c0", `null`},
+	{"93c0c2c3", `[null,false,true]`},
+	{"a161", `"a"`},
+	{"d90161", `{"$str":"a","$fmt":"str8"}`},
+	{"a1ff", `{"$strb64":"/w=="}`},
+	{"c40102", `{"$bin":"Ag=="}`},
+	{"ca3fc00000", `{"$float32":1.5}`},
+	{"cb3ff8000000000000", `1.5`},
+	{"81a16101", `{"a":1}`},
+	{"8101a161", `{"$map":[[1,"a"]]}`},
+	{"dc000101", `{"$array":[1],"$fmt":"array16"}`},
+	{"80", `{}`},
+	{"de0000", `{"$map":[],"$fmt":"map16"}`},
+	{"82a16101a16102", `{"$map":[["a",1],["a",2]]}`},
+	{"81a1618101a162", `{"a":{"$map":[[1,"b"]]}}`},
+	{"8191018102a163", `{"$map":[[[1],{"$map":[[2,"c"]]}]]}`},
+	{"81a1618181a162c0c0", `{"a":{"$map":[[{"b":null},null]]}}`},
+	{"9281a161c08101c0", `[{"a":null},{"$map":[[1,null]]}]`},
+	{"d4050a", `{"$ext":5,"$data":"Cg=="}`},
+	{"d6ff5e0d5f45", `{"$time":"2020-01-02T03:11:01Z"}`},
+	{"d7ff000000005e0d5f45", `{"$time":"2020-01-02T03:11:01Z","$fmt":"fixext8"}`},
+	{"c70cff00000000000000005e0d5f45", `{"$time":"2020-01-02T03:11:01Z","$fmt":"ext8"}`},
+
+	// timestamps that cannot be shown as such
+	{"c704ff5e0d5f45", `{"$ext":-1,"$data":"Xg1fRQ==","$fmt":"ext8"}`},
+	{"d7ffffffffff5e0d5f45", `{"$ext":-1,"$data":"/////14NX0U="}`},
+	{"c70cff000000003030303030303030", `{"$ext":-1,"$data":"AAAAADAwMDAwMDAw"}`},
+}
+
+func TestMsgpack(t *testing.T) {
+	for _, test := range msgpackTests {
+		b, _ := hex.DecodeString(test.hex)
+		s, err := msgpackCodec{}.Decode(b)
+		if err != nil || s != test.json {
+			t.Errorf("Decode(%s) = %s, %v, want %s", test.hex, s, err, test.json)
+			continue
+		}
+		if e, err := (msgpackCodec{}).Encode(s); err != nil || !bytes.Equal(e, b) {
+			t.Errorf("Encode(%s) = %x, %v, want %s", s, e, err, test.hex)
+		}
+	}
+}
+
+// msgpackNested returns a map nested n levels deep.
+func msgpackNested(n int) []byte {
+	return append(bytes.Repeat([]byte{0x81, 0x01}, n), 0xc0)
+}
+
+func TestMsgpackDepth(t *testing.T) {
+	b := msgpackNested(msgpackMaxDepth)
+	s, err := msgpackCodec{}.Decode(b)
+	if err != nil {
+		t.Fatalf("%d levels: %v", msgpackMaxDepth, err)
+	}
+	if e, err := (msgpackCodec{}).Encode(s); err != nil || !bytes.Equal(e, b) {
+		t.Errorf("%d levels: Encode = %x, %v", msgpackMaxDepth, e, err)
+	}
+
+	// deeper values used to take time quadratic in their size
+	for _, n := range []int{msgpackMaxDepth + 1, 50000} {
+		if _, err := (msgpackCodec{}).Decode(msgpackNested(n)); err != errMsgpackDepth {
+			t.Errorf("%d levels: err = %v, want errMsgpackDepth", n, err)
+		}
+	}
+	deep := strings.Repeat("[", msgpackMaxDepth+1) + strings.Repeat("]", msgpackMaxDepth+1)
+	if _, err := (msgpackCodec{}).Encode(deep); err == nil {
+		t.Errorf("Encode of %d nested arrays succeeded", msgpackMaxDepth+1)
+	}
+}
+
+// FuzzMsgpack checks that whatever decodes encodes back to the same bytes,
+// so that saving an unedited value never changes it.
+func FuzzMsgpack(f *testing.F) {
+	for _, test := range msgpackTests {
+		b, _ := hex.DecodeString(test.hex)
+		f.Add(b)
+	}
+	f.Add(msgpackNested(msgpackMaxDepth))
+	f.Add(msgpackNested(1000))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		s, err := msgpackCodec{}.Decode(b)
+		if err != nil {
+			return
+		}
+		if e, err := (msgpackCodec{}).Encode(s); err != nil || !bytes.Equal(e, b) {
+			t.Errorf("Encode(%s) = %x, %v, want %x", s, e, err, b)
+		}
+	})
+}
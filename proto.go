@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const protoPrefix = "proto:"
+
+// The message types loaded with -proto, nil if none were.
+var (
+	protoFiles *protoregistry.Files
+	protoTypes *dynamicpb.Types
+)
+
+func init() {
+	registerCodec("protowire", protowireCodec{})
+}
+
+// loadProtos loads the comma separated descriptor sets and .proto files in
+// paths. Descriptor sets are what protoc --descriptor_set_out
+// --include_imports writes; .proto files are compiled, with imports
+// resolved relative to their directory.
+func loadProtos(paths string) error {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	add := func(fd *descriptorpb.FileDescriptorProto) {
+		if !seen[fd.GetName()] {
+			seen[fd.GetName()] = true
+			set.File = append(set.File, fd)
+		}
+	}
+
+	for _, path := range strings.Split(paths, ",") {
+		if strings.HasSuffix(path, ".proto") {
+			files, err := compileProto(path)
+			if err != nil {
+				return err
+			}
+			for _, fd := range files {
+				add(fd)
+			}
+			continue
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var fds descriptorpb.FileDescriptorSet
+		if err := proto.Unmarshal(b, &fds); err != nil {
+			return fmt.Errorf("%s: not a FileDescriptorSet: %v", path, err)
+		}
+		for _, fd := range fds.File {
+			add(fd)
+		}
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return err
+	}
+	protoFiles, protoTypes = files, dynamicpb.NewTypes(files)
+	return nil
+}
+
+// compileProto compiles a .proto file and returns it with everything it
+// imports, dependencies first.
+func compileProto(path string) ([]*descriptorpb.FileDescriptorProto, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: []string{filepath.Dir(path)},
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		fds  []*descriptorpb.FileDescriptorProto
+		seen = make(map[string]bool)
+		walk func(fd protoreflect.FileDescriptor)
+	)
+	walk = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			walk(imports.Get(i).FileDescriptor)
+		}
+		fds = append(fds, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, fd := range files {
+		walk(fd)
+	}
+	return fds, nil
+}
+
+// protoMessageNames returns the codec names of all loaded message types.
+func protoMessageNames() []string {
+	if protoFiles == nil {
+		return nil
+	}
+
+	var names []string
+	var addMessages func(msgs protoreflect.MessageDescriptors)
+	addMessages = func(msgs protoreflect.MessageDescriptors) {
+		for i := 0; i < msgs.Len(); i++ {
+			if msgs.Get(i).IsMapEntry() {
+				continue
+			}
+			names = append(names, protoPrefix+string(msgs.Get(i).FullName()))
+			addMessages(msgs.Get(i).Messages())
+		}
+	}
+	protoFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		addMessages(fd.Messages())
+		return true
+	})
+	return names
+}
+
+// protoCodec shows protobuf messages of one type as JSON, in the mapping
+// protojson implements.
+type protoCodec struct {
+	desc protoreflect.MessageDescriptor
+}
+
+// lookupProto returns the codec of the message type in a "proto:" name.
+func lookupProto(name string) (Codec, error) {
+	if protoFiles == nil {
+		return nil, fmt.Errorf("codec %q: no message types loaded, see -proto", name)
+	}
+
+	d, err := protoFiles.FindDescriptorByName(protoreflect.FullName(strings.TrimPrefix(name, protoPrefix)))
+	if err != nil {
+		return nil, fmt.Errorf("codec %q: %v", name, err)
+	}
+	desc, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("codec %q: %s is not a message", name, d.FullName())
+	}
+	return protoCodec{desc}, nil
+}
+
+func (c protoCodec) Decode(b []byte) (string, error) {
+	msg := dynamicpb.NewMessage(c.desc)
+	if err := (proto.UnmarshalOptions{Resolver: protoTypes}).Unmarshal(b, msg); err != nil {
+		return "", err
+	}
+
+	js, err := protojson.MarshalOptions{Multiline: true, Indent: "  ", Resolver: protoTypes}.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	return string(js), nil
+}
+
+func (c protoCodec) Encode(s string) ([]byte, error) {
+	msg := dynamicpb.NewMessage(c.desc)
+	if err := (protojson.UnmarshalOptions{Resolver: protoTypes}).Unmarshal([]byte(s), msg); err != nil {
+		return nil, fmt.Errorf("value is not a valid %s: %v", c.desc.FullName(), err)
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+
+// protowireCodec dumps protobuf messages of unknown type field by field,
+// like protoc --decode_raw: varints in decimal, fixed width fields in hex,
+// and length delimited fields as nested messages when they parse as one or
+// as quoted strings otherwise. The dump is read-only.
+type protowireCodec struct{}
+
+func (protowireCodec) Decode(b []byte) (string, error) {
+	var buf strings.Builder
+	if err := dumpProtowire(&buf, b, ""); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (protowireCodec) Encode(s string) ([]byte, error) {
+	return nil, fmt.Errorf("protowire dumps cannot be edited, edit the raw bytes or load the message type with -proto")
+}
+
+func dumpProtowire(buf *strings.Builder, b []byte, indent string) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		fmt.Fprintf(buf, "%s%d", indent, num)
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			fmt.Fprintf(buf, ": %d\n", v)
+			b = b[n:]
+		case protowire.Fixed32Type:
+			v, n := protowire.ConsumeFixed32(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			fmt.Fprintf(buf, ": 0x%08x\n", v)
+			b = b[n:]
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			fmt.Fprintf(buf, ": 0x%016x\n", v)
+			b = b[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+
+			var nested strings.Builder
+			if len(v) > 0 && dumpProtowire(&nested, v, indent+"  ") == nil {
+				fmt.Fprintf(buf, " {\n%s%s}\n", nested.String(), indent)
+			} else {
+				fmt.Fprintf(buf, ": %s\n", strconv.Quote(string(v)))
+			}
+		default:
+			return fmt.Errorf("unsupported wire type %d", typ)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/boltdb/bolt"
+)
+
+// apiError is the body of every failed API request.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// writeJSON responds with v encoded as JSON.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	js, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(js)
+}
+
+// writeError responds with err as an apiError and the given status.
+func writeError(w http.ResponseWriter, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		log.Println(err)
+	}
+
+	js, _ := json.Marshal(apiError{err.Error()})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(js)
+}
+
+// abortDownload logs err, which stopped the download of what, and aborts
+// the response. The download is under way, so rather than end it cleanly
+// and truncated it is cut off.
+func abortDownload(what string, err error) {
+	log.Printf("%s: %v", what, err)
+	panic(http.ErrAbortHandler)
+}
+
+// errorStatus maps an error returned from a transaction to the HTTP status
+// it is reported with.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, bolt.ErrBucketNotFound),
+		errors.Is(err, errKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, bolt.ErrBucketExists),
+		errors.Is(err, errKeyExists),
+		errors.Is(err, errStageConflict):
+		return http.StatusConflict
+	case errors.Is(err, bolt.ErrBucketNameRequired),
+		errors.Is(err, bolt.ErrKeyRequired),
+		errors.Is(err, bolt.ErrKeyTooLarge),
+		errors.Is(err, bolt.ErrValueTooLarge),
+		errors.Is(err, bolt.ErrIncompatibleValue),
+		errors.Is(err, errMoveIntoItself),
+		errors.Is(err, errCopyOntoItself):
+		return http.StatusBadRequest
+	case errors.Is(err, bolt.ErrDatabaseReadOnly),
+		errors.Is(err, bolt.ErrTxNotWritable):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
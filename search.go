@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SearchHit is a matching entry together with the bucket holding it. Hits
+// are streamed to the client as JSON lines.
+type SearchHit struct {
+	Bucket BucketPath `json:"bucket"`
+	Path   string     `json:"path"`
+	Entry  Entry      `json:"entry"`
+}
+
+func searchHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	var (
+		path BucketPath
+		err  error
+	)
+	if b := r.FormValue("bucket"); b != "" {
+		path, err = parseBucketPath(b)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	match, err := newMatcher(r.FormValue("q"), r.FormValue("regex") == "true")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var inKeys, inValues bool
+	switch r.FormValue("in") {
+	case "", "both":
+		inKeys, inValues = true, true
+	case "keys":
+		inKeys = true
+	case "values":
+		inValues = true
+	default:
+		writeError(w, http.StatusBadRequest, errors.New("in must be keys, values or both"))
+		return
+	}
+
+	limit := 0
+	if l := r.FormValue("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 0 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid limit"))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	ctx := r.Context()
+	hits := 0
+
+	err = walkPath(path, func(path BucketPath, k, v []byte) error {
+		// the client went away, stop walking the database
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entry := decodeEntry(path, k, v)
+		if !(inKeys && match(entry.Key) || inValues && match(entry.Value)) {
+			return nil
+		}
+
+		if err := enc.Encode(SearchHit{path, path.String(), entry}); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if hits++; limit > 0 && hits >= limit {
+			return errSearchDone
+		}
+		return nil
+	})
+
+	switch {
+	case err == nil, err == errSearchDone, ctx.Err() != nil:
+	case hits == 0:
+		writeError(w, errorStatus(err), err)
+	default:
+		// headers are gone already, report the failure as the last line
+		enc.Encode(apiError{err.Error()})
+	}
+}
+
+var errSearchDone = errors.New("search limit reached")
+
+// newMatcher returns a predicate matching strings that contain q, or that
+// match q as a regular expression when regex is set.
+func newMatcher(q string, regex bool) (func(string) bool, error) {
+	if q == "" {
+		return nil, errors.New("empty search query")
+	}
+
+	if !regex {
+		return func(s string) bool {
+			return strings.Contains(s, q)
+		}, nil
+	}
+
+	re, err := regexp.Compile(q)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/boltdb/bolt"
+)
+
+// snapshotHandler streams a consistent copy of the database, as of the
+// start of a read transaction that writers need not wait for.
+func snapshotHandler(w http.ResponseWriter, r *http.Request) {
+	err := db.View(func(tx *bolt.Tx) error {
+		name := strings.TrimSuffix(filepath.Base(*dbpath), filepath.Ext(*dbpath))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"-snapshot"+filepath.Ext(*dbpath)))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.FormatInt(tx.Size(), 10))
+
+		_, err := tx.WriteTo(w)
+		return err
+	})
+	if err != nil {
+		abortDownload("snapshot", err)
+	}
+}
+
+// writeSnapshot writes a consistent copy of the database to the file at
+// path. The copy is written next to it and then renamed into place, so an
+// existing file is only replaced by a complete snapshot, and the database
+// itself is never a target.
+func writeSnapshot(path string) error {
+	if fi, err := os.Stat(path); err == nil {
+		dbfi, err := os.Stat(*dbpath)
+		if err != nil {
+			return err
+		}
+		if os.SameFile(fi, dbfi) {
+			return fmt.Errorf("%s is the database itself", path)
+		}
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+
+	err = db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(f)
+		return err
+	})
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/boltdb/bolt"
+)
+
+var errStageConflict = errors.New("the database changed since staging")
+
+// change is a staged op with the state of the database it was staged
+// against, which must still hold when the changeset is committed.
+type change struct {
+	id int
+	op batchOp
+
+	// existed tells whether the key, or for bucket ops the bucket, existed
+	// when the op was first staged; base is the value of the key then, or
+	// a digest of the bucket.
+	existed bool
+	base    []byte
+}
+
+// staged is the changeset of the staging mode. Like the database it is
+// shared by everyone using the server.
+var staged struct {
+	sync.Mutex
+	changes []*change
+	lastID  int
+}
+
+// StagedChange is a staged change as shown in the diff. Before is the entry
+// in the database now and After the one staged, each nil where there is
+// none; bucket changes have neither. Conflict is set when the database
+// changed since the change was staged.
+type StagedChange struct {
+	ID       int        `json:"id"`
+	Op       string     `json:"op"`
+	Bucket   BucketPath `json:"bucket"`
+	Path     string     `json:"path"`
+	Key      string     `json:"key,omitempty"`
+	Before   *Entry     `json:"before,omitempty"`
+	After    *Entry     `json:"after,omitempty"`
+	Conflict bool       `json:"conflict"`
+}
+
+// current returns whether the key of op, or for bucket ops its bucket,
+// exists in tx, and the value of the key or a digest of the bucket.
+func current(tx *bolt.Tx, op batchOp) (bool, []byte) {
+	buck, err := op.path.bucket(tx)
+	if err != nil {
+		return false, nil
+	}
+
+	switch op.op {
+	case "createBucket", "deleteBucket":
+		h := sha256.New()
+		digestBucket(h, nil, buck)
+		return true, h.Sum(nil)
+	}
+
+	v := buck.Get(op.key)
+	return v != nil, append([]byte(nil), v...)
+}
+
+func (c *change) conflicts(tx *bolt.Tx) bool {
+	existed, v := current(tx, c.op)
+	return existed != c.existed || !bytes.Equal(v, c.base)
+}
+
+// stagedExists reports whether the bucket at path exists once changes are
+// applied to tx. Buckets created below a staged delete are always staged
+// after it, as deleting a bucket drops the changes staged in it.
+func stagedExists(tx *bolt.Tx, changes []*change, path BucketPath) bool {
+	for _, c := range changes {
+		if c.op.op == "createBucket" && c.op.path.within(path) {
+			return true
+		}
+	}
+	for _, c := range changes {
+		if c.op.op == "deleteBucket" && path.within(c.op.path) {
+			return false
+		}
+	}
+	_, err := path.bucket(tx)
+	return err == nil
+}
+
+// stage adds op to changes, replacing a change staged before to the same
+// key but keeping the state it was staged against.
+func stage(tx *bolt.Tx, changes []*change, op batchOp) ([]*change, error) {
+	exists := stagedExists(tx, changes, op.path)
+	existed, base := current(tx, op)
+
+	switch op.op {
+	case "put", "delete":
+		if !exists {
+			return nil, fmt.Errorf("bucket %s: %w", op.path, bolt.ErrBucketNotFound)
+		}
+		for i, c := range changes {
+			if (c.op.op == "put" || c.op.op == "delete") &&
+				len(c.op.path) == len(op.path) && c.op.path.within(op.path) &&
+				bytes.Equal(c.op.key, op.key) {
+				changes[i] = &change{id: c.id, op: op, existed: c.existed, base: c.base}
+				return changes, nil
+			}
+		}
+	case "createBucket":
+		if exists {
+			return nil, fmt.Errorf("bucket %s: %w", op.path, bolt.ErrBucketExists)
+		}
+	case "deleteBucket":
+		if !exists {
+			return nil, fmt.Errorf("bucket %s: %w", op.path, bolt.ErrBucketNotFound)
+		}
+
+		// whatever was staged in the bucket goes with it
+		kept := changes[:0]
+		for _, c := range changes {
+			if !c.op.path.within(op.path) {
+				kept = append(kept, c)
+			}
+		}
+		changes = kept
+		if !existed {
+			// it was only staged, dropping that undoes it
+			return changes, nil
+		}
+	default:
+		return nil, fmt.Errorf("%s cannot be staged", op.op)
+	}
+
+	staged.lastID++
+	return append(changes, &change{id: staged.lastID, op: op, existed: existed, base: base}), nil
+}
+
+// stageOps adds ops to the changeset, either all or none of them.
+func stageOps(ops []batchOp) error {
+	staged.Lock()
+	defer staged.Unlock()
+
+	changes := append([]*change{}, staged.changes...)
+	err := db.View(func(tx *bolt.Tx) error {
+		for i, op := range ops {
+			var err error
+			if changes, err = stage(tx, changes, op); err != nil {
+				return fmt.Errorf("op %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	staged.changes = changes
+	return nil
+}
+
+// getStage returns the changeset as a diff against the database.
+func getStage() ([]StagedChange, error) {
+	staged.Lock()
+	defer staged.Unlock()
+
+	diff := []StagedChange{}
+	err := db.View(func(tx *bolt.Tx) error {
+		for _, c := range staged.changes {
+			d := StagedChange{
+				ID:       c.id,
+				Op:       c.op.op,
+				Bucket:   c.op.path,
+				Path:     c.op.path.String(),
+				Conflict: c.conflicts(tx),
+			}
+
+			if c.op.op == "put" || c.op.op == "delete" {
+				d.Key = decodeEntry(c.op.path, c.op.key, nil).Key
+				if existed, v := current(tx, c.op); existed {
+					before := decodeEntry(c.op.path, c.op.key, v)
+					d.Before = &before
+				}
+				if c.op.op == "put" {
+					after := decodeEntry(c.op.path, c.op.key, c.op.value)
+					d.After = &after
+				}
+			}
+			diff = append(diff, d)
+		}
+		return nil
+	})
+	return diff, err
+}
+
+// commitStage applies the changeset in one transaction and clears it. It
+// fails, committing nothing, if anything staged changed in the database
+// since, unless forced.
+func commitStage(force bool) (BatchResult, error) {
+	staged.Lock()
+	defer staged.Unlock()
+
+	ops := make([]batchOp, len(staged.changes))
+	for i, c := range staged.changes {
+		ops[i] = c.op
+	}
+
+	var res BatchResult
+	err := db.Update(func(tx *bolt.Tx) error {
+		if !force {
+			n := 0
+			for _, c := range staged.changes {
+				if c.conflicts(tx) {
+					n++
+				}
+			}
+			if n > 0 {
+				return fmt.Errorf("%w: %d staged changes", errStageConflict, n)
+			}
+		}
+
+		var err error
+		res, err = applyOps(tx, ops)
+		return err
+	})
+	if err != nil {
+		return BatchResult{}, err
+	}
+
+	staged.changes = nil
+	return res, nil
+}
+
+// discardStage drops the staged change with the given id, or with id 0 all
+// of them. It reports whether there was such a change.
+func discardStage(id int) bool {
+	staged.Lock()
+	defer staged.Unlock()
+
+	if id == 0 {
+		staged.changes = nil
+		return true
+	}
+	for i, c := range staged.changes {
+		if c.id == id {
+			staged.changes = append(staged.changes[:i:i], staged.changes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func stageHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	ops, err := parseOps(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	for i, op := range ops {
+		if op.op == "deletePrefix" || op.op == "deleteRange" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("op %d: %s cannot be staged", i, op.op))
+			return
+		}
+	}
+
+	if err := stageOps(ops); err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	getStageHandler(w, r)
+}
+
+func getStageHandler(w http.ResponseWriter, r *http.Request) {
+	diff, err := getStage()
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, diff)
+}
+
+func commitStageHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	res, err := commitStage(r.FormValue("force") == "true")
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, res)
+}
+
+func discardStageHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	id := 0
+	if s := r.FormValue("id"); s != "" {
+		var err error
+		if id, err = strconv.Atoi(s); err != nil || id < 1 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+	}
+
+	if !discardStage(id) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no staged change %d", id))
+	}
+}
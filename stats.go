@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/boltdb/bolt"
+)
+
+// Stats describes the database file and the space its buckets take.
+type Stats struct {
+	FileSize int64 `json:"fileSize"`
+	// DataSize is the size of the file up to its last page in use; the
+	// file may be larger.
+	DataSize      int64 `json:"dataSize"`
+	PageSize      int   `json:"pageSize"`
+	FreePageN     int   `json:"freePageN"`
+	PendingPageN  int   `json:"pendingPageN"`
+	FreeAlloc     int   `json:"freeAlloc"`
+	FreelistInuse int   `json:"freelistInuse"`
+	OpenTxN       int   `json:"openTxN"`
+
+	Buckets []BucketStats `json:"buckets"`
+}
+
+// BucketStats are the bolt.BucketStats of a bucket, which count its nested
+// buckets too, with the stats of each nested bucket.
+type BucketStats struct {
+	Bucket
+
+	KeyN              int `json:"keyN"`
+	Depth             int `json:"depth"`
+	BranchPageN       int `json:"branchPageN"`
+	BranchOverflowN   int `json:"branchOverflowN"`
+	LeafPageN         int `json:"leafPageN"`
+	LeafOverflowN     int `json:"leafOverflowN"`
+	BranchAlloc       int `json:"branchAlloc"`
+	BranchInuse       int `json:"branchInuse"`
+	LeafAlloc         int `json:"leafAlloc"`
+	LeafInuse         int `json:"leafInuse"`
+	BucketN           int `json:"bucketN"`
+	InlineBucketN     int `json:"inlineBucketN"`
+	InlineBucketInuse int `json:"inlineBucketInuse"`
+
+	// Fill is the part of the allocated pages in use, 0 for buckets inlined
+	// in their parent's page.
+	Fill float64 `json:"fill"`
+
+	Buckets []BucketStats `json:"buckets"`
+}
+
+func newBucketStats(name []byte, buck *bolt.Bucket) BucketStats {
+	s := buck.Stats()
+	stats := BucketStats{
+		Bucket:            newBucket(name),
+		KeyN:              s.KeyN,
+		Depth:             s.Depth,
+		BranchPageN:       s.BranchPageN,
+		BranchOverflowN:   s.BranchOverflowN,
+		LeafPageN:         s.LeafPageN,
+		LeafOverflowN:     s.LeafOverflowN,
+		BranchAlloc:       s.BranchAlloc,
+		BranchInuse:       s.BranchInuse,
+		LeafAlloc:         s.LeafAlloc,
+		LeafInuse:         s.LeafInuse,
+		BucketN:           s.BucketN,
+		InlineBucketN:     s.InlineBucketN,
+		InlineBucketInuse: s.InlineBucketInuse,
+		Buckets:           []BucketStats{},
+	}
+	if alloc := s.BranchAlloc + s.LeafAlloc; alloc > 0 {
+		stats.Fill = float64(s.BranchInuse+s.LeafInuse) / float64(alloc)
+	}
+
+	buck.ForEach(func(k, v []byte) error {
+		if v == nil {
+			stats.Buckets = append(stats.Buckets, newBucketStats(k, buck.Bucket(k)))
+		}
+		return nil
+	})
+	return stats
+}
+
+func getStats() (Stats, error) {
+	s := db.Stats()
+	stats := Stats{
+		PageSize:      db.Info().PageSize,
+		FreePageN:     s.FreePageN,
+		PendingPageN:  s.PendingPageN,
+		FreeAlloc:     s.FreeAlloc,
+		FreelistInuse: s.FreelistInuse,
+		OpenTxN:       s.OpenTxN,
+		Buckets:       []BucketStats{},
+	}
+
+	fi, err := os.Stat(*dbpath)
+	if err != nil {
+		return stats, err
+	}
+	stats.FileSize = fi.Size()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		stats.DataSize = tx.Size()
+		return tx.ForEach(func(name []byte, buck *bolt.Bucket) error {
+			stats.Buckets = append(stats.Buckets, newBucketStats(name, buck))
+			return nil
+		})
+	})
+	return stats, err
+}
+
+func getStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats, err := getStats()
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, stats)
+}